@@ -7,6 +7,7 @@ import (
 	context "context"
 	fmt "fmt"
 	proto "github.com/golang/protobuf/proto"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
@@ -24,6 +25,217 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+// Phase is how far through shutdown a job's schedule was
+type Phase int32
+
+const (
+	Phase_NOT_STARTED Phase = 0
+	Phase_RUNNING     Phase = 1
+	Phase_DRAINING    Phase = 2
+	Phase_TERMINATING Phase = 3
+	Phase_KILLING     Phase = 4
+)
+
+var Phase_name = map[int32]string{
+	0: "NOT_STARTED",
+	1: "RUNNING",
+	2: "DRAINING",
+	3: "TERMINATING",
+	4: "KILLING",
+}
+
+var Phase_value = map[string]int32{
+	"NOT_STARTED": 0,
+	"RUNNING":     1,
+	"DRAINING":    2,
+	"TERMINATING": 3,
+	"KILLING":     4,
+}
+
+func (x Phase) String() string {
+	return proto.EnumName(Phase_name, int32(x))
+}
+
+func (Phase) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{0}
+}
+
+// connections is the number of connections, 1 unless set, calls
+// to the schedule are spread across, by way of balance
+type Job_Balance int32
+
+const (
+	Job_ROUND_ROBIN       Job_Balance = 0
+	Job_LEAST_OUTSTANDING Job_Balance = 1
+)
+
+var Job_Balance_name = map[int32]string{
+	0: "ROUND_ROBIN",
+	1: "LEAST_OUTSTANDING",
+}
+
+var Job_Balance_value = map[string]int32{
+	"ROUND_ROBIN":       0,
+	"LEAST_OUTSTANDING": 1,
+}
+
+func (x Job_Balance) String() string {
+	return proto.EnumName(Job_Balance_name, int32(x))
+}
+
+func (Job_Balance) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{1, 0}
+}
+
+type Feeder_Strategy int32
+
+const (
+	Feeder_SEQUENTIAL Feeder_Strategy = 0
+	Feeder_RANDOM     Feeder_Strategy = 1
+	Feeder_UNIQUE     Feeder_Strategy = 2
+)
+
+var Feeder_Strategy_name = map[int32]string{
+	0: "SEQUENTIAL",
+	1: "RANDOM",
+	2: "UNIQUE",
+}
+
+var Feeder_Strategy_value = map[string]int32{
+	"SEQUENTIAL": 0,
+	"RANDOM":     1,
+	"UNIQUE":     2,
+}
+
+func (x Feeder_Strategy) String() string {
+	return proto.EnumName(Feeder_Strategy_name, int32(x))
+}
+
+func (Feeder_Strategy) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{4, 0}
+}
+
+type Feeder_Exhausted int32
+
+const (
+	Feeder_RECYCLE   Feeder_Exhausted = 0
+	Feeder_STOP_USER Feeder_Exhausted = 1
+	Feeder_STOP_JOB  Feeder_Exhausted = 2
+)
+
+var Feeder_Exhausted_name = map[int32]string{
+	0: "RECYCLE",
+	1: "STOP_USER",
+	2: "STOP_JOB",
+}
+
+var Feeder_Exhausted_value = map[string]int32{
+	"RECYCLE":   0,
+	"STOP_USER": 1,
+	"STOP_JOB":  2,
+}
+
+func (x Feeder_Exhausted) String() string {
+	return proto.EnumName(Feeder_Exhausted_name, int32(x))
+}
+
+func (Feeder_Exhausted) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{4, 1}
+}
+
+type ThinkTime_Distribution int32
+
+const (
+	ThinkTime_NONE        ThinkTime_Distribution = 0
+	ThinkTime_FIXED       ThinkTime_Distribution = 1
+	ThinkTime_UNIFORM     ThinkTime_Distribution = 2
+	ThinkTime_EXPONENTIAL ThinkTime_Distribution = 3
+)
+
+var ThinkTime_Distribution_name = map[int32]string{
+	0: "NONE",
+	1: "FIXED",
+	2: "UNIFORM",
+	3: "EXPONENTIAL",
+}
+
+var ThinkTime_Distribution_value = map[string]int32{
+	"NONE":        0,
+	"FIXED":       1,
+	"UNIFORM":     2,
+	"EXPONENTIAL": 3,
+}
+
+func (x ThinkTime_Distribution) String() string {
+	return proto.EnumName(ThinkTime_Distribution_name, int32(x))
+}
+
+func (ThinkTime_Distribution) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{7, 0}
+}
+
+type JobStatus_State int32
+
+const (
+	JobStatus_QUEUED    JobStatus_State = 0
+	JobStatus_RUNNING   JobStatus_State = 1
+	JobStatus_COMPLETED JobStatus_State = 2
+	JobStatus_FAILED    JobStatus_State = 3
+	JobStatus_CANCELLED JobStatus_State = 4
+)
+
+var JobStatus_State_name = map[int32]string{
+	0: "QUEUED",
+	1: "RUNNING",
+	2: "COMPLETED",
+	3: "FAILED",
+	4: "CANCELLED",
+}
+
+var JobStatus_State_value = map[string]int32{
+	"QUEUED":    0,
+	"RUNNING":   1,
+	"COMPLETED": 2,
+	"FAILED":    3,
+	"CANCELLED": 4,
+}
+
+func (x JobStatus_State) String() string {
+	return proto.EnumName(JobStatus_State_name, int32(x))
+}
+
+func (JobStatus_State) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{12, 0}
+}
+
+type LogsRequest_Stream int32
+
+const (
+	LogsRequest_BOTH   LogsRequest_Stream = 0
+	LogsRequest_STDOUT LogsRequest_Stream = 1
+	LogsRequest_STDERR LogsRequest_Stream = 2
+)
+
+var LogsRequest_Stream_name = map[int32]string{
+	0: "BOTH",
+	1: "STDOUT",
+	2: "STDERR",
+}
+
+var LogsRequest_Stream_value = map[string]int32{
+	"BOTH":   0,
+	"STDOUT": 1,
+	"STDERR": 2,
+}
+
+func (x LogsRequest_Stream) String() string {
+	return proto.EnumName(LogsRequest_Stream_name, int32(x))
+}
+
+func (LogsRequest_Stream) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{21, 0}
+}
+
 type Payload struct {
 	Version              string   `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
 	Job                  *Job     `protobuf:"bytes,2,opt,name=job,proto3" json:"job,omitempty"`
@@ -32,180 +244,1894 @@ type Payload struct {
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *Payload) Reset()         { *m = Payload{} }
-func (m *Payload) String() string { return proto.CompactTextString(m) }
-func (*Payload) ProtoMessage()    {}
-func (*Payload) Descriptor() ([]byte, []int) {
-	return fileDescriptor_56ede974c0020f77, []int{0}
+func (m *Payload) Reset()         { *m = Payload{} }
+func (m *Payload) String() string { return proto.CompactTextString(m) }
+func (*Payload) ProtoMessage()    {}
+func (*Payload) Descriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{0}
+}
+
+func (m *Payload) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Payload.Unmarshal(m, b)
+}
+func (m *Payload) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Payload.Marshal(b, m, deterministic)
+}
+func (m *Payload) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Payload.Merge(m, src)
+}
+func (m *Payload) XXX_Size() int {
+	return xxx_messageInfo_Payload.Size(m)
+}
+func (m *Payload) XXX_DiscardUnknown() {
+	xxx_messageInfo_Payload.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Payload proto.InternalMessageInfo
+
+func (m *Payload) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *Payload) GetJob() *Job {
+	if m != nil {
+		return m.Job
+	}
+	return nil
+}
+
+type Job struct {
+	Name      string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Users     uint32 `protobuf:"varint,2,opt,name=users,proto3" json:"users,omitempty"`
+	Duration  uint32 `protobuf:"varint,3,opt,name=duration,proto3" json:"duration,omitempty"`
+	Container string `protobuf:"bytes,4,opt,name=container,proto3" json:"container,omitempty"`
+	// stages, when set, replace users and duration with a load
+	// profile in which the number of users changes as the job runs
+	Stages []*Stage `protobuf:"bytes,5,rep,name=stages,proto3" json:"stages,omitempty"`
+	// rate, when set, makes calls to the schedule rate times a second,
+	// however long each call takes, rather than running a pool of users.
+	// max_in_flight caps the number of calls waiting on a response; calls
+	// over the cap are dropped
+	Rate        float64 `protobuf:"fixed64,6,opt,name=rate,proto3" json:"rate,omitempty"`
+	MaxInFlight uint32  `protobuf:"varint,7,opt,name=max_in_flight,json=maxInFlight,proto3" json:"max_in_flight,omitempty"`
+	// pacing is the time, in milliseconds, between the start of one call
+	// by a user and the start of its next. Unset, it's a second
+	Pacing    uint32     `protobuf:"varint,8,opt,name=pacing,proto3" json:"pacing,omitempty"`
+	ThinkTime *ThinkTime `protobuf:"bytes,9,opt,name=think_time,json=thinkTime,proto3" json:"think_time,omitempty"`
+	// grace_period is how long, in seconds, a finished job waits for
+	// calls already made to finish (default 10). The schedule is then sent
+	// SIGTERM, and given stop_timeout seconds (default 10) to exit before
+	// it is killed
+	GracePeriod uint32 `protobuf:"varint,10,opt,name=grace_period,json=gracePeriod,proto3" json:"grace_period,omitempty"`
+	StopTimeout uint32 `protobuf:"varint,11,opt,name=stop_timeout,json=stopTimeout,proto3" json:"stop_timeout,omitempty"`
+	// limits cap the resources the schedule can use, where the
+	// agent is able to create cgroups
+	Limits *Limits `protobuf:"bytes,12,opt,name=limits,proto3" json:"limits,omitempty"`
+	// runtime is how the schedule is run: "container" runs the image
+	// in container, "local" runs the binary at path binary on the agent,
+	// and "fake" answers calls in-process, for testing. Unset, it's
+	// "container" when container is set, and "local" otherwise
+	Runtime string `protobuf:"bytes,13,opt,name=runtime,proto3" json:"runtime,omitempty"`
+	// binary is either a path on the agent, or an http, https or file
+	// URL to fetch the binary from. Fetched binaries are verified against
+	// sha256, their hex encoded digest, and cached
+	Binary string `protobuf:"bytes,14,opt,name=binary,proto3" json:"binary,omitempty"`
+	Sha256 string `protobuf:"bytes,15,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	// signature is the base64 encoded detached ed25519 signature of
+	// binary, which agents with trusted keys require
+	Signature string `protobuf:"bytes,16,opt,name=signature,proto3" json:"signature,omitempty"`
+	// args, env and workdir are passed to the schedule. env values
+	// of the form secret:<name> are read from the file name in the
+	// agent's secrets directory, so that secrets needn't be sent in jobs
+	Args    []string          `protobuf:"bytes,17,rep,name=args,proto3" json:"args,omitempty"`
+	Env     map[string]string `protobuf:"bytes,18,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Workdir string            `protobuf:"bytes,19,opt,name=workdir,proto3" json:"workdir,omitempty"`
+	// shared jobs run alongside other shared jobs, so long as their
+	// users fit within the agent's capacity. Jobs are exclusive, running
+	// alone, by default
+	Shared      bool        `protobuf:"varint,20,opt,name=shared,proto3" json:"shared,omitempty"`
+	Connections uint32      `protobuf:"varint,21,opt,name=connections,proto3" json:"connections,omitempty"`
+	Balance     Job_Balance `protobuf:"varint,22,opt,name=balance,proto3,enum=agent.Job_Balance" json:"balance,omitempty"`
+	// transport is how the schedule is called: "rpc", the default, for
+	// schedules built with go-lo, or "grpc" for schedules serving the
+	// Schedule service in schedule.proto
+	Transport string `protobuf:"bytes,23,opt,name=transport,proto3" json:"transport,omitempty"`
+	// scenarios, when set, are the user journeys the schedule can run.
+	// Each call runs one, picked at random in proportion to its weight
+	Scenarios []*Scenario `protobuf:"bytes,24,rep,name=scenarios,proto3" json:"scenarios,omitempty"`
+	// params are sent to the schedule with every call, alongside the
+	// user, iteration and scenario the call is for
+	Params map[string]string `protobuf:"bytes,25,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// feeder, when set, sends each call a record from a file
+	Feeder *Feeder `protobuf:"bytes,26,opt,name=feeder,proto3" json:"feeder,omitempty"`
+	// thresholds are pass/fail conditions on the job's results. A job
+	// which breaches any fails
+	Thresholds []*Threshold `protobuf:"bytes,27,rep,name=thresholds,proto3" json:"thresholds,omitempty"`
+	// sinks are where the job's results are sent. Unset, they're
+	// sent to the agent's collector
+	Sinks []*Sink `protobuf:"bytes,28,rep,name=sinks,proto3" json:"sinks,omitempty"`
+	// legacy is for schedules which serve RPC on go-lo's fixed address,
+	// rather than the one the agent gives them. Only one legacy job runs
+	// at a time, and only over the rpc transport
+	Legacy               bool     `protobuf:"varint,29,opt,name=legacy,proto3" json:"legacy,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Job) Reset()         { *m = Job{} }
+func (m *Job) String() string { return proto.CompactTextString(m) }
+func (*Job) ProtoMessage()    {}
+func (*Job) Descriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{1}
+}
+
+func (m *Job) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Job.Unmarshal(m, b)
+}
+func (m *Job) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Job.Marshal(b, m, deterministic)
+}
+func (m *Job) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Job.Merge(m, src)
+}
+func (m *Job) XXX_Size() int {
+	return xxx_messageInfo_Job.Size(m)
+}
+func (m *Job) XXX_DiscardUnknown() {
+	xxx_messageInfo_Job.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Job proto.InternalMessageInfo
+
+func (m *Job) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Job) GetUsers() uint32 {
+	if m != nil {
+		return m.Users
+	}
+	return 0
+}
+
+func (m *Job) GetDuration() uint32 {
+	if m != nil {
+		return m.Duration
+	}
+	return 0
+}
+
+func (m *Job) GetContainer() string {
+	if m != nil {
+		return m.Container
+	}
+	return ""
+}
+
+func (m *Job) GetStages() []*Stage {
+	if m != nil {
+		return m.Stages
+	}
+	return nil
+}
+
+func (m *Job) GetRate() float64 {
+	if m != nil {
+		return m.Rate
+	}
+	return 0
+}
+
+func (m *Job) GetMaxInFlight() uint32 {
+	if m != nil {
+		return m.MaxInFlight
+	}
+	return 0
+}
+
+func (m *Job) GetPacing() uint32 {
+	if m != nil {
+		return m.Pacing
+	}
+	return 0
+}
+
+func (m *Job) GetThinkTime() *ThinkTime {
+	if m != nil {
+		return m.ThinkTime
+	}
+	return nil
+}
+
+func (m *Job) GetGracePeriod() uint32 {
+	if m != nil {
+		return m.GracePeriod
+	}
+	return 0
+}
+
+func (m *Job) GetStopTimeout() uint32 {
+	if m != nil {
+		return m.StopTimeout
+	}
+	return 0
+}
+
+func (m *Job) GetLimits() *Limits {
+	if m != nil {
+		return m.Limits
+	}
+	return nil
+}
+
+func (m *Job) GetRuntime() string {
+	if m != nil {
+		return m.Runtime
+	}
+	return ""
+}
+
+func (m *Job) GetBinary() string {
+	if m != nil {
+		return m.Binary
+	}
+	return ""
+}
+
+func (m *Job) GetSha256() string {
+	if m != nil {
+		return m.Sha256
+	}
+	return ""
+}
+
+func (m *Job) GetSignature() string {
+	if m != nil {
+		return m.Signature
+	}
+	return ""
+}
+
+func (m *Job) GetArgs() []string {
+	if m != nil {
+		return m.Args
+	}
+	return nil
+}
+
+func (m *Job) GetEnv() map[string]string {
+	if m != nil {
+		return m.Env
+	}
+	return nil
+}
+
+func (m *Job) GetWorkdir() string {
+	if m != nil {
+		return m.Workdir
+	}
+	return ""
+}
+
+func (m *Job) GetShared() bool {
+	if m != nil {
+		return m.Shared
+	}
+	return false
+}
+
+func (m *Job) GetConnections() uint32 {
+	if m != nil {
+		return m.Connections
+	}
+	return 0
+}
+
+func (m *Job) GetBalance() Job_Balance {
+	if m != nil {
+		return m.Balance
+	}
+	return Job_ROUND_ROBIN
+}
+
+func (m *Job) GetTransport() string {
+	if m != nil {
+		return m.Transport
+	}
+	return ""
+}
+
+func (m *Job) GetScenarios() []*Scenario {
+	if m != nil {
+		return m.Scenarios
+	}
+	return nil
+}
+
+func (m *Job) GetParams() map[string]string {
+	if m != nil {
+		return m.Params
+	}
+	return nil
+}
+
+func (m *Job) GetFeeder() *Feeder {
+	if m != nil {
+		return m.Feeder
+	}
+	return nil
+}
+
+func (m *Job) GetThresholds() []*Threshold {
+	if m != nil {
+		return m.Thresholds
+	}
+	return nil
+}
+
+func (m *Job) GetSinks() []*Sink {
+	if m != nil {
+		return m.Sinks
+	}
+	return nil
+}
+
+func (m *Job) GetLegacy() bool {
+	if m != nil {
+		return m.Legacy
+	}
+	return false
+}
+
+// Sink is where a job's results are sent: "collector", the agent's
+// collector, "file", the file at path (results.jsonl in the job's log
+// directory unless set), "prometheus", the agent's prometheus metrics,
+// or "webhook", posted in batches to url
+type Sink struct {
+	Type                 string   `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Path                 string   `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Url                  string   `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Sink) Reset()         { *m = Sink{} }
+func (m *Sink) String() string { return proto.CompactTextString(m) }
+func (*Sink) ProtoMessage()    {}
+func (*Sink) Descriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{2}
+}
+
+func (m *Sink) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Sink.Unmarshal(m, b)
+}
+func (m *Sink) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Sink.Marshal(b, m, deterministic)
+}
+func (m *Sink) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Sink.Merge(m, src)
+}
+func (m *Sink) XXX_Size() int {
+	return xxx_messageInfo_Sink.Size(m)
+}
+func (m *Sink) XXX_DiscardUnknown() {
+	xxx_messageInfo_Sink.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Sink proto.InternalMessageInfo
+
+func (m *Sink) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *Sink) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *Sink) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+// Threshold is a condition such as "p95(duration) < 300ms", "error_rate
+// < 1%" or "rps > 500", checked against every result, or only those for
+// url when it's set. abort stops the job as soon as it's breached, once
+// the job has been running for abort_after seconds
+type Threshold struct {
+	Expression           string   `protobuf:"bytes,1,opt,name=expression,proto3" json:"expression,omitempty"`
+	Url                  string   `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Abort                bool     `protobuf:"varint,3,opt,name=abort,proto3" json:"abort,omitempty"`
+	AbortAfter           uint32   `protobuf:"varint,4,opt,name=abort_after,json=abortAfter,proto3" json:"abort_after,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Threshold) Reset()         { *m = Threshold{} }
+func (m *Threshold) String() string { return proto.CompactTextString(m) }
+func (*Threshold) ProtoMessage()    {}
+func (*Threshold) Descriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{3}
+}
+
+func (m *Threshold) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Threshold.Unmarshal(m, b)
+}
+func (m *Threshold) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Threshold.Marshal(b, m, deterministic)
+}
+func (m *Threshold) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Threshold.Merge(m, src)
+}
+func (m *Threshold) XXX_Size() int {
+	return xxx_messageInfo_Threshold.Size(m)
+}
+func (m *Threshold) XXX_DiscardUnknown() {
+	xxx_messageInfo_Threshold.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Threshold proto.InternalMessageInfo
+
+func (m *Threshold) GetExpression() string {
+	if m != nil {
+		return m.Expression
+	}
+	return ""
+}
+
+func (m *Threshold) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *Threshold) GetAbort() bool {
+	if m != nil {
+		return m.Abort
+	}
+	return false
+}
+
+func (m *Threshold) GetAbortAfter() uint32 {
+	if m != nil {
+		return m.AbortAfter
+	}
+	return 0
+}
+
+// Feeder is a csv or jsonl file of records, at a path relative to the
+// agent's feeder directory or an http or https URL. Unset, format is taken
+// from path's extension.
+// sequential feeders hand out records in order, random ones at random,
+// and unique ones give each user a record of its own. When a feeder runs
+// out, it starts again from the beginning, stops users it can't give a
+// record to, or stops the job, by way of exhausted
+type Feeder struct {
+	Path                 string           `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Format               string           `protobuf:"bytes,2,opt,name=format,proto3" json:"format,omitempty"`
+	Strategy             Feeder_Strategy  `protobuf:"varint,3,opt,name=strategy,proto3,enum=agent.Feeder_Strategy" json:"strategy,omitempty"`
+	Exhausted            Feeder_Exhausted `protobuf:"varint,4,opt,name=exhausted,proto3,enum=agent.Feeder_Exhausted" json:"exhausted,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *Feeder) Reset()         { *m = Feeder{} }
+func (m *Feeder) String() string { return proto.CompactTextString(m) }
+func (*Feeder) ProtoMessage()    {}
+func (*Feeder) Descriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{4}
+}
+
+func (m *Feeder) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Feeder.Unmarshal(m, b)
+}
+func (m *Feeder) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Feeder.Marshal(b, m, deterministic)
+}
+func (m *Feeder) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Feeder.Merge(m, src)
+}
+func (m *Feeder) XXX_Size() int {
+	return xxx_messageInfo_Feeder.Size(m)
+}
+func (m *Feeder) XXX_DiscardUnknown() {
+	xxx_messageInfo_Feeder.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Feeder proto.InternalMessageInfo
+
+func (m *Feeder) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *Feeder) GetFormat() string {
+	if m != nil {
+		return m.Format
+	}
+	return ""
+}
+
+func (m *Feeder) GetStrategy() Feeder_Strategy {
+	if m != nil {
+		return m.Strategy
+	}
+	return Feeder_SEQUENTIAL
+}
+
+func (m *Feeder) GetExhausted() Feeder_Exhausted {
+	if m != nil {
+		return m.Exhausted
+	}
+	return Feeder_RECYCLE
+}
+
+// Scenario is a named user journey, run by calling method on the
+// schedule. Unset, method is "Server.Run" and weight is 1. gRPC
+// schedules are sent name rather than called on method
+type Scenario struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Method               string   `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
+	Weight               uint32   `protobuf:"varint,3,opt,name=weight,proto3" json:"weight,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Scenario) Reset()         { *m = Scenario{} }
+func (m *Scenario) String() string { return proto.CompactTextString(m) }
+func (*Scenario) ProtoMessage()    {}
+func (*Scenario) Descriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{5}
+}
+
+func (m *Scenario) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Scenario.Unmarshal(m, b)
+}
+func (m *Scenario) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Scenario.Marshal(b, m, deterministic)
+}
+func (m *Scenario) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Scenario.Merge(m, src)
+}
+func (m *Scenario) XXX_Size() int {
+	return xxx_messageInfo_Scenario.Size(m)
+}
+func (m *Scenario) XXX_DiscardUnknown() {
+	xxx_messageInfo_Scenario.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Scenario proto.InternalMessageInfo
+
+func (m *Scenario) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Scenario) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
+
+func (m *Scenario) GetWeight() uint32 {
+	if m != nil {
+		return m.Weight
+	}
+	return 0
+}
+
+// Limits are in cores, bytes and processes respectively. Unset
+// limits aren't applied
+type Limits struct {
+	Cpu                  float64  `protobuf:"fixed64,1,opt,name=cpu,proto3" json:"cpu,omitempty"`
+	Memory               uint64   `protobuf:"varint,2,opt,name=memory,proto3" json:"memory,omitempty"`
+	Pids                 uint32   `protobuf:"varint,3,opt,name=pids,proto3" json:"pids,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Limits) Reset()         { *m = Limits{} }
+func (m *Limits) String() string { return proto.CompactTextString(m) }
+func (*Limits) ProtoMessage()    {}
+func (*Limits) Descriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{6}
+}
+
+func (m *Limits) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Limits.Unmarshal(m, b)
+}
+func (m *Limits) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Limits.Marshal(b, m, deterministic)
+}
+func (m *Limits) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Limits.Merge(m, src)
+}
+func (m *Limits) XXX_Size() int {
+	return xxx_messageInfo_Limits.Size(m)
+}
+func (m *Limits) XXX_DiscardUnknown() {
+	xxx_messageInfo_Limits.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Limits proto.InternalMessageInfo
+
+func (m *Limits) GetCpu() float64 {
+	if m != nil {
+		return m.Cpu
+	}
+	return 0
+}
+
+func (m *Limits) GetMemory() uint64 {
+	if m != nil {
+		return m.Memory
+	}
+	return 0
+}
+
+func (m *Limits) GetPids() uint32 {
+	if m != nil {
+		return m.Pids
+	}
+	return 0
+}
+
+// ThinkTime is a random wait, in milliseconds, that users add
+// between calls on top of their pacing
+type ThinkTime struct {
+	Distribution         ThinkTime_Distribution `protobuf:"varint,1,opt,name=distribution,proto3,enum=agent.ThinkTime_Distribution" json:"distribution,omitempty"`
+	Min                  uint32                 `protobuf:"varint,2,opt,name=min,proto3" json:"min,omitempty"`
+	Max                  uint32                 `protobuf:"varint,3,opt,name=max,proto3" json:"max,omitempty"`
+	Mean                 uint32                 `protobuf:"varint,4,opt,name=mean,proto3" json:"mean,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
+	XXX_unrecognized     []byte                 `json:"-"`
+	XXX_sizecache        int32                  `json:"-"`
+}
+
+func (m *ThinkTime) Reset()         { *m = ThinkTime{} }
+func (m *ThinkTime) String() string { return proto.CompactTextString(m) }
+func (*ThinkTime) ProtoMessage()    {}
+func (*ThinkTime) Descriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{7}
+}
+
+func (m *ThinkTime) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ThinkTime.Unmarshal(m, b)
+}
+func (m *ThinkTime) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ThinkTime.Marshal(b, m, deterministic)
+}
+func (m *ThinkTime) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ThinkTime.Merge(m, src)
+}
+func (m *ThinkTime) XXX_Size() int {
+	return xxx_messageInfo_ThinkTime.Size(m)
+}
+func (m *ThinkTime) XXX_DiscardUnknown() {
+	xxx_messageInfo_ThinkTime.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ThinkTime proto.InternalMessageInfo
+
+func (m *ThinkTime) GetDistribution() ThinkTime_Distribution {
+	if m != nil {
+		return m.Distribution
+	}
+	return ThinkTime_NONE
+}
+
+func (m *ThinkTime) GetMin() uint32 {
+	if m != nil {
+		return m.Min
+	}
+	return 0
+}
+
+func (m *ThinkTime) GetMax() uint32 {
+	if m != nil {
+		return m.Max
+	}
+	return 0
+}
+
+func (m *ThinkTime) GetMean() uint32 {
+	if m != nil {
+		return m.Mean
+	}
+	return 0
+}
+
+type Stage struct {
+	Users                uint32   `protobuf:"varint,1,opt,name=users,proto3" json:"users,omitempty"`
+	Duration             uint32   `protobuf:"varint,2,opt,name=duration,proto3" json:"duration,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Stage) Reset()         { *m = Stage{} }
+func (m *Stage) String() string { return proto.CompactTextString(m) }
+func (*Stage) ProtoMessage()    {}
+func (*Stage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{8}
+}
+
+func (m *Stage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Stage.Unmarshal(m, b)
+}
+func (m *Stage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Stage.Marshal(b, m, deterministic)
+}
+func (m *Stage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Stage.Merge(m, src)
+}
+func (m *Stage) XXX_Size() int {
+	return xxx_messageInfo_Stage.Size(m)
+}
+func (m *Stage) XXX_DiscardUnknown() {
+	xxx_messageInfo_Stage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Stage proto.InternalMessageInfo
+
+func (m *Stage) GetUsers() uint32 {
+	if m != nil {
+		return m.Users
+	}
+	return 0
+}
+
+func (m *Stage) GetDuration() uint32 {
+	if m != nil {
+		return m.Duration
+	}
+	return 0
+}
+
+type Response struct {
+	Error                bool     `protobuf:"varint,1,opt,name=error,proto3" json:"error,omitempty"`
+	Output               string   `protobuf:"bytes,2,opt,name=output,proto3" json:"output,omitempty"`
+	Id                   string   `protobuf:"bytes,3,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Response) Reset()         { *m = Response{} }
+func (m *Response) String() string { return proto.CompactTextString(m) }
+func (*Response) ProtoMessage()    {}
+func (*Response) Descriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{9}
+}
+
+func (m *Response) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Response.Unmarshal(m, b)
+}
+func (m *Response) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Response.Marshal(b, m, deterministic)
+}
+func (m *Response) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Response.Merge(m, src)
+}
+func (m *Response) XXX_Size() int {
+	return xxx_messageInfo_Response.Size(m)
+}
+func (m *Response) XXX_DiscardUnknown() {
+	xxx_messageInfo_Response.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Response proto.InternalMessageInfo
+
+func (m *Response) GetError() bool {
+	if m != nil {
+		return m.Error
+	}
+	return false
+}
+
+func (m *Response) GetOutput() string {
+	if m != nil {
+		return m.Output
+	}
+	return ""
+}
+
+func (m *Response) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type JobID struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *JobID) Reset()         { *m = JobID{} }
+func (m *JobID) String() string { return proto.CompactTextString(m) }
+func (*JobID) ProtoMessage()    {}
+func (*JobID) Descriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{10}
+}
+
+func (m *JobID) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JobID.Unmarshal(m, b)
+}
+func (m *JobID) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JobID.Marshal(b, m, deterministic)
+}
+func (m *JobID) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JobID.Merge(m, src)
+}
+func (m *JobID) XXX_Size() int {
+	return xxx_messageInfo_JobID.Size(m)
+}
+func (m *JobID) XXX_DiscardUnknown() {
+	xxx_messageInfo_JobID.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_JobID proto.InternalMessageInfo
+
+func (m *JobID) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type ListRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListRequest) Reset()         { *m = ListRequest{} }
+func (m *ListRequest) String() string { return proto.CompactTextString(m) }
+func (*ListRequest) ProtoMessage()    {}
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{11}
+}
+
+func (m *ListRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListRequest.Unmarshal(m, b)
+}
+func (m *ListRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListRequest.Marshal(b, m, deterministic)
+}
+func (m *ListRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListRequest.Merge(m, src)
+}
+func (m *ListRequest) XXX_Size() int {
+	return xxx_messageInfo_ListRequest.Size(m)
+}
+func (m *ListRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListRequest proto.InternalMessageInfo
+
+type JobStatus struct {
+	Id       string               `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Job      *Job                 `protobuf:"bytes,2,opt,name=job,proto3" json:"job,omitempty"`
+	State    JobStatus_State      `protobuf:"varint,3,opt,name=state,proto3,enum=agent.JobStatus_State" json:"state,omitempty"`
+	Error    string               `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	Items    uint64               `protobuf:"varint,5,opt,name=items,proto3" json:"items,omitempty"`
+	Queued   *timestamp.Timestamp `protobuf:"bytes,6,opt,name=queued,proto3" json:"queued,omitempty"`
+	Started  *timestamp.Timestamp `protobuf:"bytes,7,opt,name=started,proto3" json:"started,omitempty"`
+	Finished *timestamp.Timestamp `protobuf:"bytes,8,opt,name=finished,proto3" json:"finished,omitempty"`
+	// users is the number of users the job is running right now
+	Users uint32 `protobuf:"varint,9,opt,name=users,proto3" json:"users,omitempty"`
+	// dropped is the number of calls not made because the job
+	// had max_in_flight calls waiting on a response
+	Dropped uint64 `protobuf:"varint,10,opt,name=dropped,proto3" json:"dropped,omitempty"`
+	// exited is the phase the schedule exited in; a schedule which
+	// exited while RUNNING crashed, or finished by itself
+	Exited Phase `protobuf:"varint,11,opt,name=exited,proto3,enum=agent.Phase" json:"exited,omitempty"`
+	// oom_killed is set when the schedule, or something it started,
+	// was killed for going over its memory limit
+	OomKilled bool `protobuf:"varint,12,opt,name=oom_killed,json=oomKilled,proto3" json:"oom_killed,omitempty"`
+	// connection_calls is the number of calls made on each
+	// of the job's connections to its schedule
+	ConnectionCalls []uint64 `protobuf:"varint,13,rep,packed,name=connection_calls,json=connectionCalls,proto3" json:"connection_calls,omitempty"`
+	// summary aggregates the job's results so far
+	Summary *Summary `protobuf:"bytes,14,opt,name=summary,proto3" json:"summary,omitempty"`
+	// thresholds are whether each of the job's thresholds holds, as of
+	// its results so far
+	Thresholds []*ThresholdResult `protobuf:"bytes,15,rep,name=thresholds,proto3" json:"thresholds,omitempty"`
+	// sinks are how many results were delivered to each of the job's
+	// sinks, dropped because the sink fell behind, or failed to write
+	Sinks                []*SinkStatus `protobuf:"bytes,16,rep,name=sinks,proto3" json:"sinks,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *JobStatus) Reset()         { *m = JobStatus{} }
+func (m *JobStatus) String() string { return proto.CompactTextString(m) }
+func (*JobStatus) ProtoMessage()    {}
+func (*JobStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{12}
+}
+
+func (m *JobStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JobStatus.Unmarshal(m, b)
+}
+func (m *JobStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JobStatus.Marshal(b, m, deterministic)
+}
+func (m *JobStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JobStatus.Merge(m, src)
+}
+func (m *JobStatus) XXX_Size() int {
+	return xxx_messageInfo_JobStatus.Size(m)
+}
+func (m *JobStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_JobStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_JobStatus proto.InternalMessageInfo
+
+func (m *JobStatus) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *JobStatus) GetJob() *Job {
+	if m != nil {
+		return m.Job
+	}
+	return nil
+}
+
+func (m *JobStatus) GetState() JobStatus_State {
+	if m != nil {
+		return m.State
+	}
+	return JobStatus_QUEUED
+}
+
+func (m *JobStatus) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *JobStatus) GetItems() uint64 {
+	if m != nil {
+		return m.Items
+	}
+	return 0
+}
+
+func (m *JobStatus) GetQueued() *timestamp.Timestamp {
+	if m != nil {
+		return m.Queued
+	}
+	return nil
+}
+
+func (m *JobStatus) GetStarted() *timestamp.Timestamp {
+	if m != nil {
+		return m.Started
+	}
+	return nil
+}
+
+func (m *JobStatus) GetFinished() *timestamp.Timestamp {
+	if m != nil {
+		return m.Finished
+	}
+	return nil
+}
+
+func (m *JobStatus) GetUsers() uint32 {
+	if m != nil {
+		return m.Users
+	}
+	return 0
+}
+
+func (m *JobStatus) GetDropped() uint64 {
+	if m != nil {
+		return m.Dropped
+	}
+	return 0
+}
+
+func (m *JobStatus) GetExited() Phase {
+	if m != nil {
+		return m.Exited
+	}
+	return Phase_NOT_STARTED
+}
+
+func (m *JobStatus) GetOomKilled() bool {
+	if m != nil {
+		return m.OomKilled
+	}
+	return false
+}
+
+func (m *JobStatus) GetConnectionCalls() []uint64 {
+	if m != nil {
+		return m.ConnectionCalls
+	}
+	return nil
+}
+
+func (m *JobStatus) GetSummary() *Summary {
+	if m != nil {
+		return m.Summary
+	}
+	return nil
+}
+
+func (m *JobStatus) GetThresholds() []*ThresholdResult {
+	if m != nil {
+		return m.Thresholds
+	}
+	return nil
+}
+
+func (m *JobStatus) GetSinks() []*SinkStatus {
+	if m != nil {
+		return m.Sinks
+	}
+	return nil
+}
+
+type SinkStatus struct {
+	Sink                 *Sink    `protobuf:"bytes,1,opt,name=sink,proto3" json:"sink,omitempty"`
+	Delivered            uint64   `protobuf:"varint,2,opt,name=delivered,proto3" json:"delivered,omitempty"`
+	Dropped              uint64   `protobuf:"varint,3,opt,name=dropped,proto3" json:"dropped,omitempty"`
+	Failed               uint64   `protobuf:"varint,4,opt,name=failed,proto3" json:"failed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SinkStatus) Reset()         { *m = SinkStatus{} }
+func (m *SinkStatus) String() string { return proto.CompactTextString(m) }
+func (*SinkStatus) ProtoMessage()    {}
+func (*SinkStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{13}
+}
+
+func (m *SinkStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SinkStatus.Unmarshal(m, b)
+}
+func (m *SinkStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SinkStatus.Marshal(b, m, deterministic)
+}
+func (m *SinkStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SinkStatus.Merge(m, src)
+}
+func (m *SinkStatus) XXX_Size() int {
+	return xxx_messageInfo_SinkStatus.Size(m)
+}
+func (m *SinkStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_SinkStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SinkStatus proto.InternalMessageInfo
+
+func (m *SinkStatus) GetSink() *Sink {
+	if m != nil {
+		return m.Sink
+	}
+	return nil
+}
+
+func (m *SinkStatus) GetDelivered() uint64 {
+	if m != nil {
+		return m.Delivered
+	}
+	return 0
+}
+
+func (m *SinkStatus) GetDropped() uint64 {
+	if m != nil {
+		return m.Dropped
+	}
+	return 0
+}
+
+func (m *SinkStatus) GetFailed() uint64 {
+	if m != nil {
+		return m.Failed
+	}
+	return 0
+}
+
+type ThresholdResult struct {
+	Threshold *Threshold `protobuf:"bytes,1,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	Passed    bool       `protobuf:"varint,2,opt,name=passed,proto3" json:"passed,omitempty"`
+	// observed is the value the threshold was checked against,
+	// such as "412ms" or "2.50%"
+	Observed             string   `protobuf:"bytes,3,opt,name=observed,proto3" json:"observed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ThresholdResult) Reset()         { *m = ThresholdResult{} }
+func (m *ThresholdResult) String() string { return proto.CompactTextString(m) }
+func (*ThresholdResult) ProtoMessage()    {}
+func (*ThresholdResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{14}
+}
+
+func (m *ThresholdResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ThresholdResult.Unmarshal(m, b)
+}
+func (m *ThresholdResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ThresholdResult.Marshal(b, m, deterministic)
+}
+func (m *ThresholdResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ThresholdResult.Merge(m, src)
+}
+func (m *ThresholdResult) XXX_Size() int {
+	return xxx_messageInfo_ThresholdResult.Size(m)
+}
+func (m *ThresholdResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_ThresholdResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ThresholdResult proto.InternalMessageInfo
+
+func (m *ThresholdResult) GetThreshold() *Threshold {
+	if m != nil {
+		return m.Threshold
+	}
+	return nil
+}
+
+func (m *ThresholdResult) GetPassed() bool {
+	if m != nil {
+		return m.Passed
+	}
+	return false
+}
+
+func (m *ThresholdResult) GetObserved() string {
+	if m != nil {
+		return m.Observed
+	}
+	return ""
+}
+
+// Summary aggregates a job's results, in total and broken down by url
+// and method. Only the first 1000 urls and methods are broken down
+type Summary struct {
+	Total                *Stats   `protobuf:"bytes,1,opt,name=total,proto3" json:"total,omitempty"`
+	Requests             []*Stats `protobuf:"bytes,2,rep,name=requests,proto3" json:"requests,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Summary) Reset()         { *m = Summary{} }
+func (m *Summary) String() string { return proto.CompactTextString(m) }
+func (*Summary) ProtoMessage()    {}
+func (*Summary) Descriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{15}
+}
+
+func (m *Summary) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Summary.Unmarshal(m, b)
+}
+func (m *Summary) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Summary.Marshal(b, m, deterministic)
+}
+func (m *Summary) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Summary.Merge(m, src)
+}
+func (m *Summary) XXX_Size() int {
+	return xxx_messageInfo_Summary.Size(m)
+}
+func (m *Summary) XXX_DiscardUnknown() {
+	xxx_messageInfo_Summary.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Summary proto.InternalMessageInfo
+
+func (m *Summary) GetTotal() *Stats {
+	if m != nil {
+		return m.Total
+	}
+	return nil
+}
+
+func (m *Summary) GetRequests() []*Stats {
+	if m != nil {
+		return m.Requests
+	}
+	return nil
+}
+
+// Stats describe a set of results. throughput is results a second,
+// from the first result to the last
+type Stats struct {
+	Url                  string           `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Method               string           `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
+	Count                uint64           `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	Errors               uint64           `protobuf:"varint,4,opt,name=errors,proto3" json:"errors,omitempty"`
+	ErrorRate            float64          `protobuf:"fixed64,5,opt,name=error_rate,json=errorRate,proto3" json:"error_rate,omitempty"`
+	Bytes                uint64           `protobuf:"varint,6,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	Throughput           float64          `protobuf:"fixed64,7,opt,name=throughput,proto3" json:"throughput,omitempty"`
+	Statuses             map[int32]uint64 `protobuf:"bytes,8,rep,name=statuses,proto3" json:"statuses,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	Latency              *Latency         `protobuf:"bytes,9,opt,name=latency,proto3" json:"latency,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *Stats) Reset()         { *m = Stats{} }
+func (m *Stats) String() string { return proto.CompactTextString(m) }
+func (*Stats) ProtoMessage()    {}
+func (*Stats) Descriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{16}
+}
+
+func (m *Stats) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Stats.Unmarshal(m, b)
+}
+func (m *Stats) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Stats.Marshal(b, m, deterministic)
+}
+func (m *Stats) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Stats.Merge(m, src)
+}
+func (m *Stats) XXX_Size() int {
+	return xxx_messageInfo_Stats.Size(m)
+}
+func (m *Stats) XXX_DiscardUnknown() {
+	xxx_messageInfo_Stats.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Stats proto.InternalMessageInfo
+
+func (m *Stats) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *Stats) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
+
+func (m *Stats) GetCount() uint64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+func (m *Stats) GetErrors() uint64 {
+	if m != nil {
+		return m.Errors
+	}
+	return 0
+}
+
+func (m *Stats) GetErrorRate() float64 {
+	if m != nil {
+		return m.ErrorRate
+	}
+	return 0
+}
+
+func (m *Stats) GetBytes() uint64 {
+	if m != nil {
+		return m.Bytes
+	}
+	return 0
+}
+
+func (m *Stats) GetThroughput() float64 {
+	if m != nil {
+		return m.Throughput
+	}
+	return 0
+}
+
+func (m *Stats) GetStatuses() map[int32]uint64 {
+	if m != nil {
+		return m.Statuses
+	}
+	return nil
+}
+
+func (m *Stats) GetLatency() *Latency {
+	if m != nil {
+		return m.Latency
+	}
+	return nil
+}
+
+// Latency percentiles are in nanoseconds, and within 1% of the
+// latencies they're from
+type Latency struct {
+	P50                  int64    `protobuf:"varint,1,opt,name=p50,proto3" json:"p50,omitempty"`
+	P90                  int64    `protobuf:"varint,2,opt,name=p90,proto3" json:"p90,omitempty"`
+	P95                  int64    `protobuf:"varint,3,opt,name=p95,proto3" json:"p95,omitempty"`
+	P99                  int64    `protobuf:"varint,4,opt,name=p99,proto3" json:"p99,omitempty"`
+	P999                 int64    `protobuf:"varint,5,opt,name=p999,proto3" json:"p999,omitempty"`
+	Max                  int64    `protobuf:"varint,6,opt,name=max,proto3" json:"max,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Latency) Reset()         { *m = Latency{} }
+func (m *Latency) String() string { return proto.CompactTextString(m) }
+func (*Latency) ProtoMessage()    {}
+func (*Latency) Descriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{17}
+}
+
+func (m *Latency) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Latency.Unmarshal(m, b)
+}
+func (m *Latency) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Latency.Marshal(b, m, deterministic)
+}
+func (m *Latency) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Latency.Merge(m, src)
+}
+func (m *Latency) XXX_Size() int {
+	return xxx_messageInfo_Latency.Size(m)
+}
+func (m *Latency) XXX_DiscardUnknown() {
+	xxx_messageInfo_Latency.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Latency proto.InternalMessageInfo
+
+func (m *Latency) GetP50() int64 {
+	if m != nil {
+		return m.P50
+	}
+	return 0
+}
+
+func (m *Latency) GetP90() int64 {
+	if m != nil {
+		return m.P90
+	}
+	return 0
+}
+
+func (m *Latency) GetP95() int64 {
+	if m != nil {
+		return m.P95
+	}
+	return 0
+}
+
+func (m *Latency) GetP99() int64 {
+	if m != nil {
+		return m.P99
+	}
+	return 0
+}
+
+func (m *Latency) GetP999() int64 {
+	if m != nil {
+		return m.P999
+	}
+	return 0
+}
+
+func (m *Latency) GetMax() int64 {
+	if m != nil {
+		return m.Max
+	}
+	return 0
+}
+
+type JobList struct {
+	Jobs                 []*JobStatus `protobuf:"bytes,1,rep,name=jobs,proto3" json:"jobs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *JobList) Reset()         { *m = JobList{} }
+func (m *JobList) String() string { return proto.CompactTextString(m) }
+func (*JobList) ProtoMessage()    {}
+func (*JobList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{18}
+}
+
+func (m *JobList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JobList.Unmarshal(m, b)
+}
+func (m *JobList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JobList.Marshal(b, m, deterministic)
+}
+func (m *JobList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JobList.Merge(m, src)
+}
+func (m *JobList) XXX_Size() int {
+	return xxx_messageInfo_JobList.Size(m)
+}
+func (m *JobList) XXX_DiscardUnknown() {
+	xxx_messageInfo_JobList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_JobList proto.InternalMessageInfo
+
+func (m *JobList) GetJobs() []*JobStatus {
+	if m != nil {
+		return m.Jobs
+	}
+	return nil
+}
+
+type WatchRequest struct {
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// sample is the proportion of results to stream, between 0 and 1;
+	// 0.1 sends every tenth result. Unset, or 0, streams every result
+	Sample               float64  `protobuf:"fixed64,2,opt,name=sample,proto3" json:"sample,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WatchRequest) Reset()         { *m = WatchRequest{} }
+func (m *WatchRequest) String() string { return proto.CompactTextString(m) }
+func (*WatchRequest) ProtoMessage()    {}
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{19}
 }
 
-func (m *Payload) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Payload.Unmarshal(m, b)
+func (m *WatchRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchRequest.Unmarshal(m, b)
 }
-func (m *Payload) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Payload.Marshal(b, m, deterministic)
+func (m *WatchRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchRequest.Marshal(b, m, deterministic)
 }
-func (m *Payload) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Payload.Merge(m, src)
+func (m *WatchRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchRequest.Merge(m, src)
 }
-func (m *Payload) XXX_Size() int {
-	return xxx_messageInfo_Payload.Size(m)
+func (m *WatchRequest) XXX_Size() int {
+	return xxx_messageInfo_WatchRequest.Size(m)
 }
-func (m *Payload) XXX_DiscardUnknown() {
-	xxx_messageInfo_Payload.DiscardUnknown(m)
+func (m *WatchRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_Payload proto.InternalMessageInfo
+var xxx_messageInfo_WatchRequest proto.InternalMessageInfo
 
-func (m *Payload) GetVersion() string {
+func (m *WatchRequest) GetId() string {
 	if m != nil {
-		return m.Version
+		return m.Id
 	}
 	return ""
 }
 
-func (m *Payload) GetJob() *Job {
+func (m *WatchRequest) GetSample() float64 {
 	if m != nil {
-		return m.Job
+		return m.Sample
 	}
-	return nil
+	return 0
 }
 
-type Job struct {
-	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Users                uint32   `protobuf:"varint,2,opt,name=users,proto3" json:"users,omitempty"`
-	Duration             uint32   `protobuf:"varint,3,opt,name=duration,proto3" json:"duration,omitempty"`
-	Container            string   `protobuf:"bytes,4,opt,name=container,proto3" json:"container,omitempty"`
+type Result struct {
+	SequenceId string               `protobuf:"bytes,1,opt,name=sequence_id,json=sequenceId,proto3" json:"sequence_id,omitempty"`
+	Url        string               `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Method     string               `protobuf:"bytes,3,opt,name=method,proto3" json:"method,omitempty"`
+	Status     int32                `protobuf:"varint,4,opt,name=status,proto3" json:"status,omitempty"`
+	Size       int64                `protobuf:"varint,5,opt,name=size,proto3" json:"size,omitempty"`
+	Timestamp  *timestamp.Timestamp `protobuf:"bytes,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Duration   int64                `protobuf:"varint,7,opt,name=duration,proto3" json:"duration,omitempty"`
+	Error      string               `protobuf:"bytes,8,opt,name=error,proto3" json:"error,omitempty"`
+	// scenario is the name of the scenario which made the result
+	Scenario             string   `protobuf:"bytes,9,opt,name=scenario,proto3" json:"scenario,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *Job) Reset()         { *m = Job{} }
-func (m *Job) String() string { return proto.CompactTextString(m) }
-func (*Job) ProtoMessage()    {}
-func (*Job) Descriptor() ([]byte, []int) {
-	return fileDescriptor_56ede974c0020f77, []int{1}
+func (m *Result) Reset()         { *m = Result{} }
+func (m *Result) String() string { return proto.CompactTextString(m) }
+func (*Result) ProtoMessage()    {}
+func (*Result) Descriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{20}
 }
 
-func (m *Job) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Job.Unmarshal(m, b)
+func (m *Result) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Result.Unmarshal(m, b)
 }
-func (m *Job) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Job.Marshal(b, m, deterministic)
+func (m *Result) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Result.Marshal(b, m, deterministic)
 }
-func (m *Job) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Job.Merge(m, src)
+func (m *Result) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Result.Merge(m, src)
 }
-func (m *Job) XXX_Size() int {
-	return xxx_messageInfo_Job.Size(m)
+func (m *Result) XXX_Size() int {
+	return xxx_messageInfo_Result.Size(m)
 }
-func (m *Job) XXX_DiscardUnknown() {
-	xxx_messageInfo_Job.DiscardUnknown(m)
+func (m *Result) XXX_DiscardUnknown() {
+	xxx_messageInfo_Result.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_Job proto.InternalMessageInfo
+var xxx_messageInfo_Result proto.InternalMessageInfo
 
-func (m *Job) GetName() string {
+func (m *Result) GetSequenceId() string {
 	if m != nil {
-		return m.Name
+		return m.SequenceId
 	}
 	return ""
 }
 
-func (m *Job) GetUsers() uint32 {
+func (m *Result) GetUrl() string {
 	if m != nil {
-		return m.Users
+		return m.Url
+	}
+	return ""
+}
+
+func (m *Result) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
+
+func (m *Result) GetStatus() int32 {
+	if m != nil {
+		return m.Status
 	}
 	return 0
 }
 
-func (m *Job) GetDuration() uint32 {
+func (m *Result) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *Result) GetTimestamp() *timestamp.Timestamp {
+	if m != nil {
+		return m.Timestamp
+	}
+	return nil
+}
+
+func (m *Result) GetDuration() int64 {
 	if m != nil {
 		return m.Duration
 	}
 	return 0
 }
 
-func (m *Job) GetContainer() string {
+func (m *Result) GetError() string {
 	if m != nil {
-		return m.Container
+		return m.Error
 	}
 	return ""
 }
 
-type Response struct {
-	Error                bool     `protobuf:"varint,1,opt,name=error,proto3" json:"error,omitempty"`
-	Output               string   `protobuf:"bytes,2,opt,name=output,proto3" json:"output,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *Result) GetScenario() string {
+	if m != nil {
+		return m.Scenario
+	}
+	return ""
 }
 
-func (m *Response) Reset()         { *m = Response{} }
-func (m *Response) String() string { return proto.CompactTextString(m) }
-func (*Response) ProtoMessage()    {}
-func (*Response) Descriptor() ([]byte, []int) {
-	return fileDescriptor_56ede974c0020f77, []int{2}
+type LogsRequest struct {
+	Id                   string             `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Follow               bool               `protobuf:"varint,2,opt,name=follow,proto3" json:"follow,omitempty"`
+	Stream               LogsRequest_Stream `protobuf:"varint,3,opt,name=stream,proto3,enum=agent.LogsRequest_Stream" json:"stream,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
 }
 
-func (m *Response) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Response.Unmarshal(m, b)
+func (m *LogsRequest) Reset()         { *m = LogsRequest{} }
+func (m *LogsRequest) String() string { return proto.CompactTextString(m) }
+func (*LogsRequest) ProtoMessage()    {}
+func (*LogsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{21}
 }
-func (m *Response) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Response.Marshal(b, m, deterministic)
+
+func (m *LogsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LogsRequest.Unmarshal(m, b)
 }
-func (m *Response) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Response.Merge(m, src)
+func (m *LogsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LogsRequest.Marshal(b, m, deterministic)
 }
-func (m *Response) XXX_Size() int {
-	return xxx_messageInfo_Response.Size(m)
+func (m *LogsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LogsRequest.Merge(m, src)
 }
-func (m *Response) XXX_DiscardUnknown() {
-	xxx_messageInfo_Response.DiscardUnknown(m)
+func (m *LogsRequest) XXX_Size() int {
+	return xxx_messageInfo_LogsRequest.Size(m)
+}
+func (m *LogsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_LogsRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_Response proto.InternalMessageInfo
+var xxx_messageInfo_LogsRequest proto.InternalMessageInfo
 
-func (m *Response) GetError() bool {
+func (m *LogsRequest) GetId() string {
 	if m != nil {
-		return m.Error
+		return m.Id
+	}
+	return ""
+}
+
+func (m *LogsRequest) GetFollow() bool {
+	if m != nil {
+		return m.Follow
 	}
 	return false
 }
 
-func (m *Response) GetOutput() string {
+func (m *LogsRequest) GetStream() LogsRequest_Stream {
 	if m != nil {
-		return m.Output
+		return m.Stream
+	}
+	return LogsRequest_BOTH
+}
+
+type LogLine struct {
+	Stream               LogsRequest_Stream `protobuf:"varint,1,opt,name=stream,proto3,enum=agent.LogsRequest_Stream" json:"stream,omitempty"`
+	Line                 string             `protobuf:"bytes,2,opt,name=line,proto3" json:"line,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *LogLine) Reset()         { *m = LogLine{} }
+func (m *LogLine) String() string { return proto.CompactTextString(m) }
+func (*LogLine) ProtoMessage()    {}
+func (*LogLine) Descriptor() ([]byte, []int) {
+	return fileDescriptor_56ede974c0020f77, []int{22}
+}
+
+func (m *LogLine) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LogLine.Unmarshal(m, b)
+}
+func (m *LogLine) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LogLine.Marshal(b, m, deterministic)
+}
+func (m *LogLine) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LogLine.Merge(m, src)
+}
+func (m *LogLine) XXX_Size() int {
+	return xxx_messageInfo_LogLine.Size(m)
+}
+func (m *LogLine) XXX_DiscardUnknown() {
+	xxx_messageInfo_LogLine.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LogLine proto.InternalMessageInfo
+
+func (m *LogLine) GetStream() LogsRequest_Stream {
+	if m != nil {
+		return m.Stream
+	}
+	return LogsRequest_BOTH
+}
+
+func (m *LogLine) GetLine() string {
+	if m != nil {
+		return m.Line
 	}
 	return ""
 }
 
 func init() {
+	proto.RegisterEnum("agent.Phase", Phase_name, Phase_value)
+	proto.RegisterEnum("agent.Job_Balance", Job_Balance_name, Job_Balance_value)
+	proto.RegisterEnum("agent.Feeder_Strategy", Feeder_Strategy_name, Feeder_Strategy_value)
+	proto.RegisterEnum("agent.Feeder_Exhausted", Feeder_Exhausted_name, Feeder_Exhausted_value)
+	proto.RegisterEnum("agent.ThinkTime_Distribution", ThinkTime_Distribution_name, ThinkTime_Distribution_value)
+	proto.RegisterEnum("agent.JobStatus_State", JobStatus_State_name, JobStatus_State_value)
+	proto.RegisterEnum("agent.LogsRequest_Stream", LogsRequest_Stream_name, LogsRequest_Stream_value)
 	proto.RegisterType((*Payload)(nil), "agent.Payload")
 	proto.RegisterType((*Job)(nil), "agent.Job")
+	proto.RegisterMapType((map[string]string)(nil), "agent.Job.EnvEntry")
+	proto.RegisterMapType((map[string]string)(nil), "agent.Job.ParamsEntry")
+	proto.RegisterType((*Sink)(nil), "agent.Sink")
+	proto.RegisterType((*Threshold)(nil), "agent.Threshold")
+	proto.RegisterType((*Feeder)(nil), "agent.Feeder")
+	proto.RegisterType((*Scenario)(nil), "agent.Scenario")
+	proto.RegisterType((*Limits)(nil), "agent.Limits")
+	proto.RegisterType((*ThinkTime)(nil), "agent.ThinkTime")
+	proto.RegisterType((*Stage)(nil), "agent.Stage")
 	proto.RegisterType((*Response)(nil), "agent.Response")
+	proto.RegisterType((*JobID)(nil), "agent.JobID")
+	proto.RegisterType((*ListRequest)(nil), "agent.ListRequest")
+	proto.RegisterType((*JobStatus)(nil), "agent.JobStatus")
+	proto.RegisterType((*SinkStatus)(nil), "agent.SinkStatus")
+	proto.RegisterType((*ThresholdResult)(nil), "agent.ThresholdResult")
+	proto.RegisterType((*Summary)(nil), "agent.Summary")
+	proto.RegisterType((*Stats)(nil), "agent.Stats")
+	proto.RegisterMapType((map[int32]uint64)(nil), "agent.Stats.StatusesEntry")
+	proto.RegisterType((*Latency)(nil), "agent.Latency")
+	proto.RegisterType((*JobList)(nil), "agent.JobList")
+	proto.RegisterType((*WatchRequest)(nil), "agent.WatchRequest")
+	proto.RegisterType((*Result)(nil), "agent.Result")
+	proto.RegisterType((*LogsRequest)(nil), "agent.LogsRequest")
+	proto.RegisterType((*LogLine)(nil), "agent.LogLine")
 }
 
 func init() { proto.RegisterFile("agent.proto", fileDescriptor_56ede974c0020f77) }
 
 var fileDescriptor_56ede974c0020f77 = []byte{
-	// 229 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x44, 0x90, 0xc1, 0x4a, 0xc3, 0x40,
-	0x10, 0x86, 0x8d, 0x69, 0xd2, 0x64, 0x8a, 0x0a, 0x83, 0x48, 0x28, 0x3d, 0x94, 0x9c, 0x0a, 0x42,
-	0x0f, 0xd5, 0x83, 0xd7, 0xe2, 0xad, 0x27, 0xd9, 0x37, 0xd8, 0xd8, 0x41, 0x22, 0xba, 0x13, 0x66,
-	0x77, 0x05, 0xdf, 0x5e, 0x32, 0xd9, 0xd8, 0xdb, 0x7e, 0xb3, 0xcc, 0xff, 0xed, 0xbf, 0xb0, 0xb2,
-	0x1f, 0xe4, 0xc2, 0x7e, 0x10, 0x0e, 0x8c, 0x85, 0x42, 0x7b, 0x84, 0xe5, 0x9b, 0xfd, 0xfd, 0x62,
-	0x7b, 0xc6, 0x06, 0x96, 0x3f, 0x24, 0xbe, 0x67, 0xd7, 0x64, 0xdb, 0x6c, 0x57, 0x9b, 0x19, 0x71,
-	0x03, 0xf9, 0x27, 0x77, 0xcd, 0xf5, 0x36, 0xdb, 0xad, 0x0e, 0xb0, 0x9f, 0x62, 0x4e, 0xdc, 0x99,
-	0x71, 0xdc, 0xf6, 0x90, 0x9f, 0xb8, 0x43, 0x84, 0x85, 0xb3, 0xdf, 0x94, 0x76, 0xf5, 0x8c, 0xf7,
-	0x50, 0x44, 0x4f, 0xe2, 0x75, 0xf5, 0xc6, 0x4c, 0x80, 0x6b, 0xa8, 0xce, 0x51, 0x6c, 0x18, 0x4d,
-	0xb9, 0x5e, 0xfc, 0x33, 0x6e, 0xa0, 0x7e, 0x67, 0x17, 0x6c, 0xef, 0x48, 0x9a, 0x85, 0x46, 0x5d,
-	0x06, 0xed, 0x0b, 0x54, 0x86, 0xfc, 0xc0, 0xce, 0x6b, 0x36, 0x89, 0xb0, 0xa8, 0xb0, 0x32, 0x13,
-	0xe0, 0x03, 0x94, 0x1c, 0xc3, 0x10, 0x83, 0x2a, 0x6b, 0x93, 0xe8, 0xf0, 0x0c, 0xc5, 0x71, 0x7c,
-	0x36, 0x3e, 0x42, 0xf9, 0x2a, 0x64, 0x03, 0xe1, 0x6d, 0x2a, 0x92, 0xfa, 0xaf, 0xef, 0x12, 0xcf,
-	0x86, 0xf6, 0xaa, 0x2b, 0xf5, 0xaf, 0x9e, 0xfe, 0x02, 0x00, 0x00, 0xff, 0xff, 0x7f, 0x11, 0xa8,
-	0x5f, 0x3a, 0x01, 0x00, 0x00,
+	// 2101 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x58, 0x4b, 0x73, 0x1b, 0xc7,
+	0x11, 0xe6, 0xe2, 0x8d, 0x06, 0x1f, 0xd0, 0x58, 0x96, 0xd6, 0x88, 0x14, 0xd1, 0x5b, 0x76, 0x85,
+	0x76, 0x14, 0x48, 0x46, 0x42, 0x95, 0x99, 0x5c, 0x02, 0x11, 0x60, 0x0c, 0x1a, 0x02, 0xa8, 0x01,
+	0x50, 0x76, 0x4e, 0xa8, 0x01, 0x30, 0x04, 0xd6, 0xdc, 0x07, 0xbc, 0x33, 0x4b, 0x91, 0xa9, 0xca,
+	0x39, 0x55, 0x39, 0xe7, 0x94, 0xdf, 0x90, 0x3f, 0x92, 0x73, 0x4e, 0xf9, 0x37, 0xa9, 0x9e, 0x99,
+	0x5d, 0x2c, 0x48, 0xf9, 0x91, 0x5b, 0xf7, 0xd7, 0x3d, 0xaf, 0x7e, 0xef, 0x42, 0x8d, 0x2d, 0x79,
+	0x20, 0x9b, 0xeb, 0x28, 0x94, 0x21, 0x29, 0x2a, 0xa6, 0xf1, 0x6c, 0x19, 0x86, 0x4b, 0x8f, 0xbf,
+	0x50, 0xe0, 0x2c, 0xbe, 0x7c, 0x21, 0x5d, 0x9f, 0x0b, 0xc9, 0xfc, 0xb5, 0xd6, 0x73, 0xda, 0x50,
+	0xbe, 0x60, 0xb7, 0x5e, 0xc8, 0x16, 0xc4, 0x86, 0xf2, 0x35, 0x8f, 0x84, 0x1b, 0x06, 0xb6, 0x75,
+	0x68, 0x1d, 0x55, 0x69, 0xc2, 0x92, 0x27, 0x90, 0xff, 0x2e, 0x9c, 0xd9, 0xb9, 0x43, 0xeb, 0xa8,
+	0xd6, 0x82, 0xa6, 0x3e, 0xe7, 0x3c, 0x9c, 0x51, 0x84, 0x9d, 0xff, 0x56, 0x20, 0x7f, 0x1e, 0xce,
+	0x08, 0x81, 0x42, 0xc0, 0x7c, 0x6e, 0x16, 0x2b, 0x9a, 0x3c, 0x84, 0x62, 0x2c, 0x78, 0x24, 0xd4,
+	0xda, 0x3d, 0xaa, 0x19, 0xd2, 0x80, 0xca, 0x22, 0x8e, 0x98, 0xc4, 0xa3, 0xf2, 0x4a, 0x90, 0xf2,
+	0xe4, 0x09, 0x54, 0xe7, 0x61, 0x20, 0x99, 0x1b, 0xf0, 0xc8, 0x2e, 0xa8, 0xad, 0x36, 0x00, 0xf9,
+	0x04, 0x4a, 0x42, 0xb2, 0x25, 0x17, 0x76, 0xf1, 0x30, 0x7f, 0x54, 0x6b, 0xed, 0x9a, 0xcb, 0x8c,
+	0x10, 0xa4, 0x46, 0x86, 0x37, 0x89, 0x98, 0xe4, 0x76, 0xe9, 0xd0, 0x3a, 0xb2, 0xa8, 0xa2, 0x89,
+	0x03, 0x7b, 0x3e, 0xbb, 0x99, 0xba, 0xc1, 0xf4, 0xd2, 0x73, 0x97, 0x2b, 0x69, 0x97, 0xd5, 0xc1,
+	0x35, 0x9f, 0xdd, 0xf4, 0x82, 0x33, 0x05, 0x91, 0x47, 0x50, 0x5a, 0xb3, 0xb9, 0x1b, 0x2c, 0xed,
+	0x8a, 0x12, 0x1a, 0x8e, 0xbc, 0x00, 0x90, 0x2b, 0x37, 0xb8, 0x9a, 0xa2, 0xf5, 0xec, 0xaa, 0x32,
+	0x43, 0xdd, 0x9c, 0x3c, 0x46, 0xc1, 0xd8, 0xf5, 0x39, 0xad, 0xca, 0x84, 0x24, 0x1f, 0xc3, 0xee,
+	0x32, 0x62, 0x73, 0x3e, 0x5d, 0xf3, 0xc8, 0x0d, 0x17, 0x36, 0xe8, 0xb3, 0x14, 0x76, 0xa1, 0x20,
+	0x54, 0x11, 0x32, 0x5c, 0xab, 0x2d, 0xc3, 0x58, 0xda, 0x35, 0xad, 0x82, 0xd8, 0x58, 0x43, 0xe4,
+	0x53, 0x28, 0x79, 0xae, 0xef, 0x4a, 0x61, 0xef, 0xaa, 0x23, 0xf7, 0xcc, 0x91, 0x7d, 0x05, 0x52,
+	0x23, 0x44, 0xbf, 0x45, 0x71, 0xa0, 0xae, 0xb6, 0xa7, 0xfd, 0x66, 0x58, 0x7c, 0xcf, 0xcc, 0x0d,
+	0x58, 0x74, 0x6b, 0xef, 0x2b, 0x81, 0xe1, 0x10, 0x17, 0x2b, 0xd6, 0x3a, 0x7e, 0x65, 0x1f, 0x68,
+	0x5c, 0x73, 0x68, 0x7b, 0xe1, 0x2e, 0x03, 0x26, 0xe3, 0x88, 0xdb, 0x75, 0x6d, 0xfb, 0x14, 0x40,
+	0xab, 0xb2, 0x68, 0x29, 0xec, 0x07, 0x87, 0x79, 0xf4, 0x2f, 0xd2, 0xe4, 0x53, 0xc8, 0xf3, 0xe0,
+	0xda, 0x26, 0xca, 0x19, 0x1f, 0x6c, 0x22, 0xa3, 0xd9, 0x0d, 0xae, 0xbb, 0x81, 0x8c, 0x6e, 0x29,
+	0xca, 0xf1, 0x8a, 0xef, 0xc2, 0xe8, 0x6a, 0xe1, 0x46, 0xf6, 0x07, 0xfa, 0x8a, 0x86, 0x35, 0x57,
+	0x89, 0xf8, 0xc2, 0x7e, 0x78, 0x68, 0x1d, 0x55, 0xa8, 0xe1, 0xc8, 0x21, 0xd4, 0xe6, 0x61, 0x10,
+	0xf0, 0x39, 0x06, 0x85, 0xb0, 0x3f, 0xd4, 0xd6, 0xc9, 0x40, 0xe4, 0x39, 0x94, 0x67, 0xcc, 0x63,
+	0xc1, 0x9c, 0xdb, 0x8f, 0x0e, 0xad, 0xa3, 0xfd, 0x16, 0xc9, 0x1c, 0xff, 0x5a, 0x4b, 0x68, 0xa2,
+	0x82, 0x4f, 0x93, 0x11, 0x0b, 0xc4, 0x3a, 0x8c, 0xa4, 0xfd, 0x58, 0x3f, 0x2d, 0x05, 0xc8, 0x6f,
+	0xa0, 0x2a, 0xe6, 0x3c, 0x60, 0x91, 0x1b, 0x0a, 0xdb, 0x56, 0x8f, 0x39, 0x48, 0x22, 0xcb, 0xe0,
+	0x74, 0xa3, 0x41, 0x9a, 0x18, 0x27, 0x11, 0xf3, 0x85, 0xfd, 0x91, 0xd2, 0x7d, 0x94, 0x39, 0xf9,
+	0x42, 0x09, 0xf4, 0xdb, 0x8d, 0x16, 0x3a, 0xf2, 0x92, 0xf3, 0x05, 0x8f, 0xec, 0xc6, 0x96, 0x23,
+	0xcf, 0x14, 0x48, 0x8d, 0x90, 0xbc, 0xc4, 0x30, 0x8b, 0xb8, 0x58, 0x85, 0xde, 0x42, 0xd8, 0xbf,
+	0x38, 0xcc, 0x6f, 0x85, 0x99, 0x11, 0xd0, 0x8c, 0x0e, 0xf9, 0x18, 0x8a, 0xc2, 0x0d, 0xae, 0x84,
+	0xfd, 0x44, 0x29, 0xd7, 0x92, 0x3b, 0xbb, 0xc1, 0x15, 0xd5, 0x12, 0x34, 0xb0, 0xc7, 0x97, 0x6c,
+	0x7e, 0x6b, 0x3f, 0xd5, 0x06, 0xd6, 0x5c, 0xe3, 0x15, 0x54, 0x12, 0x1f, 0x91, 0x3a, 0xe4, 0xaf,
+	0xf8, 0xad, 0x49, 0x5c, 0x24, 0x31, 0x6f, 0xaf, 0x99, 0x17, 0x73, 0x95, 0xb7, 0x55, 0xaa, 0x99,
+	0xdf, 0xe7, 0xbe, 0xb4, 0x1a, 0x27, 0x50, 0xcb, 0x3c, 0xf1, 0xff, 0x59, 0xea, 0x7c, 0x01, 0x65,
+	0xe3, 0x17, 0x72, 0x00, 0x35, 0x3a, 0x9c, 0x0c, 0x3a, 0x53, 0x3a, 0x7c, 0xdd, 0x1b, 0xd4, 0x77,
+	0xc8, 0x87, 0xf0, 0xa0, 0xdf, 0x6d, 0x8f, 0xc6, 0xd3, 0xe1, 0x64, 0x3c, 0x1a, 0xb7, 0x07, 0x9d,
+	0xde, 0xe0, 0x4f, 0x75, 0xcb, 0xf9, 0x23, 0x14, 0xf0, 0x31, 0x18, 0x7b, 0xf2, 0x76, 0x9d, 0xd6,
+	0x16, 0xa4, 0x11, 0x5b, 0x33, 0xb9, 0x32, 0xe7, 0x28, 0x1a, 0xaf, 0x13, 0x47, 0x9e, 0x2a, 0x2a,
+	0x55, 0x8a, 0xa4, 0x23, 0xa1, 0x9a, 0xda, 0x8e, 0xfc, 0x12, 0x80, 0xdf, 0xac, 0x23, 0x2e, 0x32,
+	0x55, 0x2e, 0x83, 0x24, 0xcb, 0x73, 0xe9, 0x72, 0x7c, 0x0d, 0x9b, 0x61, 0xcc, 0xe4, 0x95, 0xf5,
+	0x34, 0x43, 0x9e, 0x41, 0x4d, 0x11, 0x53, 0x76, 0x29, 0x4d, 0x99, 0xda, 0xa3, 0xa0, 0xa0, 0x36,
+	0x22, 0xce, 0xdf, 0x73, 0x50, 0xd2, 0xde, 0x4d, 0xaf, 0x69, 0x65, 0xae, 0xf9, 0x08, 0x4a, 0x97,
+	0x61, 0xe4, 0x33, 0x69, 0x8e, 0x32, 0x1c, 0x69, 0x41, 0x45, 0x48, 0x2c, 0x57, 0xcb, 0x5b, 0x75,
+	0xe0, 0x7e, 0x1a, 0x5a, 0x7a, 0xb3, 0xe6, 0xc8, 0x48, 0x69, 0xaa, 0x47, 0x8e, 0xa1, 0xca, 0x6f,
+	0x56, 0x2c, 0x16, 0x92, 0x2f, 0xd4, 0x4d, 0xf6, 0x5b, 0x8f, 0xb7, 0x17, 0x75, 0x13, 0x31, 0xdd,
+	0x68, 0x3a, 0x2d, 0xa8, 0x24, 0x9b, 0x91, 0x7d, 0x80, 0x51, 0xf7, 0xed, 0xa4, 0x3b, 0x18, 0xf7,
+	0xda, 0xfd, 0xfa, 0x0e, 0x01, 0x28, 0xd1, 0xf6, 0xa0, 0x33, 0x7c, 0x53, 0xb7, 0x90, 0x9e, 0x0c,
+	0x7a, 0x6f, 0x27, 0xdd, 0x7a, 0xce, 0x39, 0x86, 0x6a, 0xba, 0x17, 0xa9, 0x41, 0x99, 0x76, 0x4f,
+	0xff, 0x7c, 0xda, 0xef, 0xd6, 0x77, 0xc8, 0x1e, 0x54, 0x47, 0xe3, 0xe1, 0xc5, 0x74, 0x32, 0xea,
+	0xd2, 0xba, 0x45, 0x76, 0xa1, 0xa2, 0xd8, 0xf3, 0xe1, 0xeb, 0x7a, 0xce, 0x19, 0x40, 0x25, 0xc9,
+	0xa2, 0xf7, 0x36, 0x89, 0x47, 0x50, 0xf2, 0xb9, 0x5c, 0x85, 0x8b, 0xc4, 0x1a, 0x9a, 0x43, 0xfc,
+	0x1d, 0x57, 0xb5, 0x5a, 0x37, 0x09, 0xc3, 0x39, 0x67, 0x50, 0xd2, 0x25, 0x10, 0xfd, 0x35, 0x5f,
+	0xc7, 0x6a, 0x33, 0x8b, 0x22, 0xa9, 0xf7, 0xf2, 0xc3, 0xe8, 0x56, 0xed, 0x55, 0xa0, 0x86, 0x53,
+	0x5e, 0x70, 0x17, 0xc2, 0xec, 0xa4, 0x68, 0xe7, 0xdf, 0x16, 0xc6, 0x46, 0x52, 0xb3, 0xdb, 0xb0,
+	0xbb, 0x70, 0x85, 0x8c, 0xdc, 0x59, 0x2c, 0x93, 0xe8, 0xd8, 0x6f, 0x3d, 0xbd, 0x5b, 0xe6, 0x9b,
+	0x9d, 0x8c, 0x12, 0xdd, 0x5a, 0x82, 0xd7, 0xf1, 0xdd, 0xc0, 0xf4, 0x3a, 0x24, 0x15, 0xc2, 0x6e,
+	0xcc, 0xa9, 0x48, 0xe2, 0x45, 0x7c, 0xce, 0x02, 0x13, 0x33, 0x8a, 0x76, 0xda, 0xb0, 0x9b, 0xdd,
+	0x95, 0x54, 0xa0, 0x30, 0x18, 0x0e, 0xd0, 0xae, 0x55, 0x28, 0x9e, 0xf5, 0xbe, 0xed, 0x76, 0xea,
+	0x16, 0xda, 0x7b, 0x32, 0xe8, 0x9d, 0x0d, 0xe9, 0x9b, 0x7a, 0x0e, 0xf3, 0xa7, 0xfb, 0xed, 0xc5,
+	0x70, 0x60, 0x5c, 0x96, 0x77, 0x4e, 0xa0, 0xa8, 0x7a, 0xe0, 0xa6, 0xe3, 0x5a, 0x3f, 0xd4, 0x71,
+	0x73, 0xdb, 0x1d, 0xd7, 0xf9, 0x0a, 0x2a, 0x94, 0x8b, 0x75, 0x18, 0x08, 0xb5, 0x9a, 0x47, 0x51,
+	0x18, 0xa9, 0xd5, 0x15, 0xaa, 0x19, 0x34, 0x6a, 0x18, 0xcb, 0x75, 0x9c, 0x86, 0xab, 0xe6, 0xc8,
+	0x3e, 0xe4, 0xdc, 0x85, 0x49, 0xb6, 0x9c, 0xbb, 0x70, 0x1e, 0x43, 0xf1, 0x3c, 0x9c, 0xf5, 0x3a,
+	0x46, 0x60, 0xa5, 0x82, 0x3d, 0xa8, 0xf5, 0x5d, 0x21, 0x29, 0xff, 0x3e, 0xe6, 0x42, 0x3a, 0xff,
+	0x2a, 0x42, 0xf5, 0x3c, 0x9c, 0x8d, 0x24, 0x93, 0xb1, 0xb8, 0xab, 0xfc, 0xe3, 0xd3, 0x06, 0x79,
+	0x0e, 0x45, 0x21, 0xb1, 0xb9, 0x6f, 0xe7, 0x47, 0xba, 0x1d, 0x8e, 0x02, 0x92, 0x53, 0xad, 0xb4,
+	0x79, 0x8f, 0x9e, 0x24, 0xcc, 0x7b, 0x1e, 0x42, 0xd1, 0x95, 0xdc, 0xc7, 0x21, 0x02, 0x63, 0x44,
+	0x33, 0xa4, 0x05, 0xa5, 0xef, 0x63, 0x1e, 0xf3, 0x85, 0x9a, 0x1b, 0x6a, 0xad, 0x46, 0x53, 0x0f,
+	0x4f, 0xcd, 0x64, 0x78, 0x6a, 0x8e, 0x93, 0xe1, 0x89, 0x1a, 0x4d, 0xf2, 0x3b, 0x28, 0x0b, 0xc9,
+	0x22, 0x4c, 0xbd, 0xf2, 0x4f, 0x2e, 0x4a, 0x54, 0xc9, 0x2b, 0xa8, 0x5c, 0xba, 0x81, 0x2b, 0x56,
+	0x7c, 0x61, 0x57, 0x7e, 0x72, 0x59, 0xaa, 0xbb, 0xf1, 0x6d, 0x35, 0xeb, 0x5b, 0x1b, 0xca, 0x8b,
+	0x28, 0x5c, 0xaf, 0xb9, 0x9e, 0x33, 0x0a, 0x34, 0x61, 0x71, 0x5a, 0xe2, 0x37, 0x2e, 0x5e, 0xae,
+	0xa6, 0x8c, 0x95, 0x4c, 0x4b, 0x17, 0x2b, 0x26, 0x38, 0x35, 0x32, 0xf2, 0x14, 0x20, 0x0c, 0xfd,
+	0xe9, 0x95, 0xeb, 0x79, 0x7c, 0xa1, 0x46, 0x8d, 0x0a, 0xad, 0x86, 0xa1, 0xff, 0xb5, 0x02, 0xc8,
+	0x67, 0x50, 0xdf, 0xb4, 0xdd, 0xe9, 0x9c, 0x79, 0x9e, 0xb0, 0xf7, 0x0e, 0xf3, 0x47, 0x05, 0x7a,
+	0xb0, 0xc1, 0x4f, 0x11, 0x26, 0x47, 0x50, 0x16, 0xb1, 0xef, 0x27, 0x03, 0x47, 0xad, 0xb5, 0x9f,
+	0x34, 0x24, 0x8d, 0xd2, 0x44, 0x4c, 0x5e, 0x6d, 0xb5, 0xba, 0x83, 0xad, 0x2e, 0xba, 0x69, 0x75,
+	0x5c, 0xc4, 0x9e, 0xdc, 0x6a, 0x78, 0xbf, 0x4a, 0x1a, 0x5e, 0x5d, 0x2d, 0x79, 0x90, 0x69, 0x78,
+	0xda, 0xfd, 0xa6, 0xed, 0x39, 0xe7, 0x2a, 0x1f, 0x24, 0xc7, 0xfa, 0xf5, 0x76, 0xd2, 0x9d, 0x74,
+	0x3b, 0xf5, 0x1d, 0x55, 0xb2, 0x26, 0x83, 0x81, 0x6a, 0x2d, 0x58, 0xb2, 0x4e, 0x87, 0x6f, 0x2e,
+	0xfa, 0xdd, 0x71, 0xb7, 0x53, 0xcf, 0xa1, 0xde, 0x59, 0xbb, 0xd7, 0xef, 0x76, 0xea, 0x79, 0x25,
+	0x6a, 0x0f, 0x4e, 0xbb, 0x7d, 0x64, 0x0b, 0xce, 0x5f, 0x01, 0x36, 0x07, 0x90, 0x67, 0x50, 0xc0,
+	0x23, 0x54, 0xc0, 0xde, 0x69, 0xb9, 0x4a, 0x80, 0xa3, 0xc6, 0x82, 0x7b, 0xee, 0x35, 0xc7, 0xa9,
+	0x46, 0x57, 0xa1, 0x0d, 0x90, 0xf5, 0x56, 0x7e, 0xdb, 0x5b, 0xd8, 0x14, 0x98, 0xeb, 0x99, 0x2a,
+	0x5e, 0xa0, 0x86, 0x73, 0x62, 0x38, 0xb8, 0x63, 0x12, 0xd2, 0x84, 0x6a, 0x6a, 0x14, 0x73, 0x91,
+	0xfb, 0x83, 0xc2, 0x46, 0x45, 0x0f, 0xb6, 0x42, 0x98, 0xfb, 0x54, 0xa8, 0xe1, 0xb0, 0x2c, 0x84,
+	0x33, 0xc1, 0xa3, 0x6b, 0x9e, 0xa4, 0x71, 0xca, 0x3b, 0xdf, 0x40, 0xd9, 0xb8, 0x8d, 0x38, 0x50,
+	0x94, 0xa1, 0x64, 0x9e, 0x39, 0x2a, 0x33, 0x74, 0x4b, 0x41, 0xb5, 0x88, 0x1c, 0x41, 0x25, 0xd2,
+	0xe9, 0x8d, 0xc3, 0x7e, 0xfe, 0x9e, 0x5a, 0x2a, 0x75, 0xfe, 0x93, 0xd3, 0xbe, 0x11, 0x49, 0xbb,
+	0xb5, 0x36, 0xed, 0xf6, 0x87, 0x5a, 0xc1, 0x43, 0x28, 0xce, 0xc3, 0x38, 0x90, 0xc6, 0x66, 0x9a,
+	0x41, 0x6d, 0x95, 0xd0, 0x22, 0xb1, 0x98, 0xe6, 0x30, 0xa2, 0x15, 0x35, 0x55, 0x5f, 0x01, 0x45,
+	0xd5, 0x1d, 0xaa, 0x0a, 0xa1, 0xa6, 0x28, 0xcc, 0x6e, 0x25, 0x17, 0x2a, 0xcf, 0x0b, 0x54, 0x33,
+	0x38, 0x1b, 0xc8, 0x55, 0x14, 0xc6, 0xcb, 0x15, 0x16, 0xba, 0xb2, 0x5a, 0x94, 0x41, 0x30, 0x69,
+	0x85, 0x8a, 0x00, 0x2e, 0xec, 0x8a, 0x7a, 0x60, 0x23, 0xfb, 0xc0, 0xe6, 0xc8, 0x08, 0xf5, 0xe8,
+	0x97, 0xea, 0x62, 0x52, 0x78, 0x4c, 0xf2, 0x60, 0x7e, 0x6b, 0x57, 0xb7, 0x92, 0xa2, 0xaf, 0x51,
+	0x9a, 0x88, 0x1b, 0x7f, 0x80, 0xbd, 0xad, 0x4d, 0xb2, 0xc3, 0x55, 0xf1, 0x3d, 0xc3, 0x55, 0x21,
+	0x3b, 0x5c, 0xbd, 0x83, 0xb2, 0xd9, 0x10, 0x97, 0xad, 0x8f, 0x5f, 0xaa, 0x65, 0x79, 0x8a, 0xa4,
+	0x42, 0x4e, 0x5e, 0xda, 0x39, 0x83, 0x9c, 0x18, 0xe4, 0xd8, 0xce, 0x27, 0xc8, 0xb1, 0x46, 0x4e,
+	0xec, 0x42, 0x82, 0x9c, 0xa8, 0x9e, 0x79, 0x72, 0x72, 0xa2, 0x0c, 0x98, 0xa7, 0x8a, 0x4e, 0x1a,
+	0x5a, 0x49, 0x6b, 0xf9, 0xec, 0xc6, 0x79, 0x01, 0xe5, 0xf3, 0x70, 0x86, 0xe5, 0x9d, 0x7c, 0x02,
+	0x85, 0xef, 0xc2, 0x19, 0xb6, 0x9e, 0xec, 0xe8, 0x9a, 0x96, 0x66, 0xaa, 0xa4, 0xce, 0x2b, 0xd8,
+	0xfd, 0x86, 0xc9, 0xf9, 0xca, 0x74, 0x83, 0x7b, 0xf5, 0x1f, 0x3f, 0x09, 0x98, 0xbf, 0xf6, 0xf4,
+	0x23, 0x2d, 0x6a, 0x38, 0xe7, 0x6f, 0x39, 0x28, 0x99, 0xf8, 0x7f, 0x06, 0x35, 0x81, 0xab, 0x83,
+	0x39, 0x9f, 0xa6, 0x6b, 0x21, 0x81, 0x7a, 0x8b, 0xf7, 0x0c, 0x72, 0x9b, 0xc8, 0xca, 0xdf, 0x1d,
+	0x32, 0xb4, 0xab, 0xd4, 0xcb, 0x8b, 0xd4, 0x70, 0xf8, 0x78, 0xe1, 0xfe, 0x85, 0x27, 0x8f, 0x47,
+	0x9a, 0x7c, 0x09, 0xd5, 0xf4, 0xfb, 0xf9, 0x67, 0x34, 0x89, 0x8d, 0xf2, 0x56, 0xff, 0x2d, 0xab,
+	0x1d, 0x53, 0x7e, 0xd3, 0xa3, 0x2a, 0xd9, 0x1e, 0xd5, 0x80, 0x4a, 0xf2, 0xc1, 0xa1, 0xe2, 0xa6,
+	0x4a, 0x53, 0xde, 0xf9, 0x87, 0x05, 0xb5, 0x7e, 0xb8, 0x14, 0x3f, 0x62, 0xc1, 0xcb, 0xd0, 0xf3,
+	0xc2, 0x77, 0x49, 0xba, 0x6b, 0x8e, 0x7c, 0x81, 0x6f, 0x8d, 0x38, 0xf3, 0x4d, 0xf3, 0xfc, 0x28,
+	0x89, 0xc4, 0xcd, 0x5e, 0x38, 0x61, 0x72, 0xe6, 0x53, 0xa3, 0xe8, 0x7c, 0x0e, 0x25, 0x8d, 0xe0,
+	0x50, 0xf2, 0x7a, 0x38, 0xfe, 0x4a, 0x8f, 0x87, 0xa3, 0x71, 0x67, 0x38, 0x19, 0xeb, 0xf1, 0x70,
+	0x34, 0xee, 0x74, 0x29, 0xad, 0xe7, 0x9c, 0x0b, 0x28, 0xf7, 0xc3, 0x65, 0xdf, 0x0d, 0x78, 0xe6,
+	0x24, 0xeb, 0x67, 0x9e, 0x84, 0x06, 0xf7, 0xdc, 0x20, 0xf9, 0x6c, 0x50, 0xf4, 0xe7, 0x6f, 0xa1,
+	0xa8, 0x7a, 0x15, 0xce, 0x3b, 0x83, 0xe1, 0x78, 0x3a, 0x1a, 0xb7, 0xe9, 0xf8, 0x7e, 0x29, 0xdf,
+	0x85, 0x4a, 0x87, 0xb6, 0x7b, 0x8a, 0x53, 0xb3, 0xd1, 0xb8, 0x4b, 0xdf, 0xf4, 0x06, 0xed, 0x31,
+	0x02, 0x79, 0xd4, 0xfd, 0xba, 0xd7, 0xef, 0x23, 0x53, 0x68, 0xfd, 0x33, 0x07, 0xc5, 0x36, 0xde,
+	0x85, 0xfc, 0x1a, 0x4a, 0xa7, 0x11, 0xc7, 0x82, 0x90, 0x64, 0xa4, 0xf9, 0x13, 0xd2, 0x48, 0xbe,
+	0xfd, 0x92, 0xb1, 0xc8, 0xd9, 0x21, 0xca, 0x0e, 0x2a, 0x30, 0x76, 0x37, 0x61, 0xdd, 0xeb, 0x34,
+	0xee, 0x05, 0xb9, 0xb3, 0x43, 0x9e, 0x43, 0x41, 0xa5, 0x03, 0x49, 0xbf, 0xd7, 0xd3, 0xd1, 0xa7,
+	0xb1, 0xbf, 0xd1, 0x47, 0xd8, 0xd9, 0x21, 0x9f, 0x41, 0xe9, 0x14, 0xbf, 0x89, 0xbc, 0x3b, 0x3b,
+	0xbf, 0xe7, 0x12, 0x2f, 0xa0, 0xa8, 0x32, 0x87, 0x24, 0x5f, 0xda, 0xd9, 0x3c, 0x6a, 0xec, 0x6d,
+	0x16, 0xc4, 0x9e, 0x74, 0x76, 0x5e, 0x5a, 0xa4, 0x09, 0x05, 0xb4, 0x38, 0x21, 0xf7, 0xcd, 0x9f,
+	0xde, 0xc4, 0xb8, 0x0c, 0xf5, 0x67, 0x25, 0x15, 0xc5, 0xbf, 0xfd, 0xdf, 0x00, 0x3f, 0x00, 0x44,
+	0xd0, 0x4b, 0x12, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -221,6 +2147,11 @@ const _ = grpc.SupportPackageIsVersion4
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type AgentClient interface {
 	Create(ctx context.Context, in *Payload, opts ...grpc.CallOption) (*Response, error)
+	Status(ctx context.Context, in *JobID, opts ...grpc.CallOption) (*JobStatus, error)
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*JobList, error)
+	Cancel(ctx context.Context, in *JobID, opts ...grpc.CallOption) (*Response, error)
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Agent_WatchClient, error)
+	Logs(ctx context.Context, in *LogsRequest, opts ...grpc.CallOption) (Agent_LogsClient, error)
 }
 
 type agentClient struct {
@@ -240,9 +2171,105 @@ func (c *agentClient) Create(ctx context.Context, in *Payload, opts ...grpc.Call
 	return out, nil
 }
 
+func (c *agentClient) Status(ctx context.Context, in *JobID, opts ...grpc.CallOption) (*JobStatus, error) {
+	out := new(JobStatus)
+	err := c.cc.Invoke(ctx, "/agent.Agent/Status", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*JobList, error) {
+	out := new(JobList)
+	err := c.cc.Invoke(ctx, "/agent.Agent/List", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentClient) Cancel(ctx context.Context, in *JobID, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/agent.Agent/Cancel", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Agent_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Agent_serviceDesc.Streams[0], "/agent.Agent/Watch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &agentWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Agent_WatchClient interface {
+	Recv() (*Result, error)
+	grpc.ClientStream
+}
+
+type agentWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *agentWatchClient) Recv() (*Result, error) {
+	m := new(Result)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *agentClient) Logs(ctx context.Context, in *LogsRequest, opts ...grpc.CallOption) (Agent_LogsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Agent_serviceDesc.Streams[1], "/agent.Agent/Logs", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &agentLogsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Agent_LogsClient interface {
+	Recv() (*LogLine, error)
+	grpc.ClientStream
+}
+
+type agentLogsClient struct {
+	grpc.ClientStream
+}
+
+func (x *agentLogsClient) Recv() (*LogLine, error) {
+	m := new(LogLine)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // AgentServer is the server API for Agent service.
 type AgentServer interface {
 	Create(context.Context, *Payload) (*Response, error)
+	Status(context.Context, *JobID) (*JobStatus, error)
+	List(context.Context, *ListRequest) (*JobList, error)
+	Cancel(context.Context, *JobID) (*Response, error)
+	Watch(*WatchRequest, Agent_WatchServer) error
+	Logs(*LogsRequest, Agent_LogsServer) error
 }
 
 // UnimplementedAgentServer can be embedded to have forward compatible implementations.
@@ -252,6 +2279,21 @@ type UnimplementedAgentServer struct {
 func (*UnimplementedAgentServer) Create(ctx context.Context, req *Payload) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
 }
+func (*UnimplementedAgentServer) Status(ctx context.Context, req *JobID) (*JobStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
+}
+func (*UnimplementedAgentServer) List(ctx context.Context, req *ListRequest) (*JobList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (*UnimplementedAgentServer) Cancel(ctx context.Context, req *JobID) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Cancel not implemented")
+}
+func (*UnimplementedAgentServer) Watch(req *WatchRequest, srv Agent_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (*UnimplementedAgentServer) Logs(req *LogsRequest, srv Agent_LogsServer) error {
+	return status.Errorf(codes.Unimplemented, "method Logs not implemented")
+}
 
 func RegisterAgentServer(s *grpc.Server, srv AgentServer) {
 	s.RegisterService(&_Agent_serviceDesc, srv)
@@ -275,6 +2317,102 @@ func _Agent_Create_Handler(srv interface{}, ctx context.Context, dec func(interf
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Agent_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agent.Agent/Status",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).Status(ctx, req.(*JobID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Agent_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agent.Agent/List",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).List(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Agent_Cancel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).Cancel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agent.Agent/Cancel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).Cancel(ctx, req.(*JobID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Agent_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AgentServer).Watch(m, &agentWatchServer{stream})
+}
+
+type Agent_WatchServer interface {
+	Send(*Result) error
+	grpc.ServerStream
+}
+
+type agentWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *agentWatchServer) Send(m *Result) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Agent_Logs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(LogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AgentServer).Logs(m, &agentLogsServer{stream})
+}
+
+type Agent_LogsServer interface {
+	Send(*LogLine) error
+	grpc.ServerStream
+}
+
+type agentLogsServer struct {
+	grpc.ServerStream
+}
+
+func (x *agentLogsServer) Send(m *LogLine) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _Agent_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "agent.Agent",
 	HandlerType: (*AgentServer)(nil),
@@ -283,7 +2421,30 @@ var _Agent_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Create",
 			Handler:    _Agent_Create_Handler,
 		},
+		{
+			MethodName: "Status",
+			Handler:    _Agent_Status_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _Agent_List_Handler,
+		},
+		{
+			MethodName: "Cancel",
+			Handler:    _Agent_Cancel_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _Agent_Watch_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Logs",
+			Handler:       _Agent_Logs_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "agent.proto",
 }
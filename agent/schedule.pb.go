@@ -0,0 +1,377 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: schedule.proto
+
+package agent
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type ReadyRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReadyRequest) Reset()         { *m = ReadyRequest{} }
+func (m *ReadyRequest) String() string { return proto.CompactTextString(m) }
+func (*ReadyRequest) ProtoMessage()    {}
+func (*ReadyRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{0}
+}
+
+func (m *ReadyRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReadyRequest.Unmarshal(m, b)
+}
+func (m *ReadyRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReadyRequest.Marshal(b, m, deterministic)
+}
+func (m *ReadyRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReadyRequest.Merge(m, src)
+}
+func (m *ReadyRequest) XXX_Size() int {
+	return xxx_messageInfo_ReadyRequest.Size(m)
+}
+func (m *ReadyRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReadyRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReadyRequest proto.InternalMessageInfo
+
+type ReadyResponse struct {
+	Ready                bool     `protobuf:"varint,1,opt,name=ready,proto3" json:"ready,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReadyResponse) Reset()         { *m = ReadyResponse{} }
+func (m *ReadyResponse) String() string { return proto.CompactTextString(m) }
+func (*ReadyResponse) ProtoMessage()    {}
+func (*ReadyResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{1}
+}
+
+func (m *ReadyResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReadyResponse.Unmarshal(m, b)
+}
+func (m *ReadyResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReadyResponse.Marshal(b, m, deterministic)
+}
+func (m *ReadyResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReadyResponse.Merge(m, src)
+}
+func (m *ReadyResponse) XXX_Size() int {
+	return xxx_messageInfo_ReadyResponse.Size(m)
+}
+func (m *ReadyResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReadyResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReadyResponse proto.InternalMessageInfo
+
+func (m *ReadyResponse) GetReady() bool {
+	if m != nil {
+		return m.Ready
+	}
+	return false
+}
+
+type RunRequest struct {
+	// scenario is the name of the scenario to run, for jobs with
+	// scenarios
+	Scenario string `protobuf:"bytes,1,opt,name=scenario,proto3" json:"scenario,omitempty"`
+	// user is the index of the virtual user making the call, and
+	// iteration the number of calls that user has made before it.
+	// Jobs with a rate have no users; their user is always 0, and
+	// iteration counts every call
+	User      uint32 `protobuf:"varint,2,opt,name=user,proto3" json:"user,omitempty"`
+	Iteration uint64 `protobuf:"varint,3,opt,name=iteration,proto3" json:"iteration,omitempty"`
+	// job and run_id are the name and ID of the job, and params the
+	// params it was created with
+	Job    string            `protobuf:"bytes,4,opt,name=job,proto3" json:"job,omitempty"`
+	RunId  string            `protobuf:"bytes,5,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	Params map[string]string `protobuf:"bytes,6,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// record is from the job's feeder, if it has one
+	Record               map[string]string `protobuf:"bytes,7,rep,name=record,proto3" json:"record,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *RunRequest) Reset()         { *m = RunRequest{} }
+func (m *RunRequest) String() string { return proto.CompactTextString(m) }
+func (*RunRequest) ProtoMessage()    {}
+func (*RunRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{2}
+}
+
+func (m *RunRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RunRequest.Unmarshal(m, b)
+}
+func (m *RunRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RunRequest.Marshal(b, m, deterministic)
+}
+func (m *RunRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RunRequest.Merge(m, src)
+}
+func (m *RunRequest) XXX_Size() int {
+	return xxx_messageInfo_RunRequest.Size(m)
+}
+func (m *RunRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RunRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RunRequest proto.InternalMessageInfo
+
+func (m *RunRequest) GetScenario() string {
+	if m != nil {
+		return m.Scenario
+	}
+	return ""
+}
+
+func (m *RunRequest) GetUser() uint32 {
+	if m != nil {
+		return m.User
+	}
+	return 0
+}
+
+func (m *RunRequest) GetIteration() uint64 {
+	if m != nil {
+		return m.Iteration
+	}
+	return 0
+}
+
+func (m *RunRequest) GetJob() string {
+	if m != nil {
+		return m.Job
+	}
+	return ""
+}
+
+func (m *RunRequest) GetRunId() string {
+	if m != nil {
+		return m.RunId
+	}
+	return ""
+}
+
+func (m *RunRequest) GetParams() map[string]string {
+	if m != nil {
+		return m.Params
+	}
+	return nil
+}
+
+func (m *RunRequest) GetRecord() map[string]string {
+	if m != nil {
+		return m.Record
+	}
+	return nil
+}
+
+type RunResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RunResponse) Reset()         { *m = RunResponse{} }
+func (m *RunResponse) String() string { return proto.CompactTextString(m) }
+func (*RunResponse) ProtoMessage()    {}
+func (*RunResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d00842e68e05382a, []int{3}
+}
+
+func (m *RunResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RunResponse.Unmarshal(m, b)
+}
+func (m *RunResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RunResponse.Marshal(b, m, deterministic)
+}
+func (m *RunResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RunResponse.Merge(m, src)
+}
+func (m *RunResponse) XXX_Size() int {
+	return xxx_messageInfo_RunResponse.Size(m)
+}
+func (m *RunResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RunResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RunResponse proto.InternalMessageInfo
+
+func init() {
+	proto.RegisterType((*ReadyRequest)(nil), "agent.ReadyRequest")
+	proto.RegisterType((*ReadyResponse)(nil), "agent.ReadyResponse")
+	proto.RegisterType((*RunRequest)(nil), "agent.RunRequest")
+	proto.RegisterMapType((map[string]string)(nil), "agent.RunRequest.ParamsEntry")
+	proto.RegisterMapType((map[string]string)(nil), "agent.RunRequest.RecordEntry")
+	proto.RegisterType((*RunResponse)(nil), "agent.RunResponse")
+}
+
+func init() { proto.RegisterFile("schedule.proto", fileDescriptor_d00842e68e05382a) }
+
+var fileDescriptor_d00842e68e05382a = []byte{
+	// 320 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x52, 0x41, 0x4b, 0xf3, 0x40,
+	0x10, 0xfd, 0xd2, 0x34, 0xf9, 0xda, 0xa9, 0x2d, 0x3a, 0x56, 0x58, 0x82, 0x42, 0x08, 0x08, 0x39,
+	0xe5, 0x50, 0x15, 0xd4, 0xbb, 0x07, 0x6f, 0xb2, 0xfe, 0x00, 0xd9, 0x36, 0x83, 0x46, 0xeb, 0x6e,
+	0xdc, 0xcd, 0x0a, 0xfd, 0xc5, 0xfe, 0x0d, 0xd9, 0x4d, 0x6a, 0x23, 0xe2, 0xc1, 0xdb, 0xbc, 0xb7,
+	0xef, 0x0d, 0xf3, 0xf2, 0x02, 0x33, 0xb3, 0x7a, 0xa2, 0xd2, 0xae, 0xa9, 0xa8, 0xb5, 0x6a, 0x14,
+	0x46, 0xe2, 0x91, 0x64, 0x93, 0xcd, 0x60, 0x8f, 0x93, 0x28, 0x37, 0x9c, 0xde, 0x2c, 0x99, 0x26,
+	0x3b, 0x85, 0x69, 0x87, 0x4d, 0xad, 0xa4, 0x21, 0x9c, 0x43, 0xa4, 0x1d, 0xc1, 0x82, 0x34, 0xc8,
+	0x47, 0xbc, 0x05, 0xd9, 0xc7, 0x00, 0x80, 0x5b, 0xd9, 0xb9, 0x30, 0x81, 0x91, 0x59, 0x91, 0x14,
+	0xba, 0x52, 0x5e, 0x37, 0xe6, 0x5f, 0x18, 0x11, 0x86, 0xd6, 0x90, 0x66, 0x83, 0x34, 0xc8, 0xa7,
+	0xdc, 0xcf, 0x78, 0x0c, 0xe3, 0xaa, 0x21, 0x2d, 0x9a, 0x4a, 0x49, 0x16, 0xa6, 0x41, 0x3e, 0xe4,
+	0x3b, 0x02, 0xf7, 0x21, 0x7c, 0x56, 0x4b, 0x36, 0xf4, 0x8b, 0xdc, 0x88, 0x47, 0x10, 0x6b, 0x2b,
+	0x1f, 0xaa, 0x92, 0x45, 0x9e, 0x8c, 0xb4, 0x95, 0xb7, 0x25, 0x5e, 0x40, 0x5c, 0x0b, 0x2d, 0x5e,
+	0x0d, 0x8b, 0xd3, 0x30, 0x9f, 0x2c, 0x4e, 0x0a, 0x1f, 0xaa, 0xd8, 0x5d, 0x56, 0xdc, 0xf9, 0xf7,
+	0x1b, 0xd9, 0xe8, 0x0d, 0xef, 0xc4, 0xce, 0xa6, 0x69, 0xa5, 0x74, 0xc9, 0xfe, 0xff, 0x66, 0xe3,
+	0xfe, 0xbd, 0xb3, 0xb5, 0xe2, 0xe4, 0x0a, 0x26, 0xbd, 0x6d, 0xee, 0xca, 0x17, 0xda, 0x74, 0x71,
+	0xdd, 0xe8, 0x3e, 0xd5, 0xbb, 0x58, 0x5b, 0xf2, 0x51, 0xc7, 0xbc, 0x05, 0xd7, 0x83, 0xcb, 0xc0,
+	0x59, 0x7b, 0x1b, 0xff, 0x62, 0xcd, 0xa6, 0x30, 0xf1, 0x77, 0xb5, 0x75, 0x2c, 0x6a, 0x18, 0xdd,
+	0x77, 0x45, 0xe2, 0x39, 0x44, 0xbe, 0x2b, 0x3c, 0xdc, 0x06, 0xe8, 0x35, 0x99, 0xcc, 0xbf, 0x93,
+	0xad, 0x3f, 0xfb, 0x87, 0x05, 0x84, 0xdc, 0x4a, 0x3c, 0xf8, 0x11, 0x3a, 0xc1, 0x3e, 0xb5, 0xd5,
+	0x2f, 0x63, 0xff, 0xbf, 0x9c, 0x7d, 0x0e, 0x00, 0xfa, 0x14, 0x03, 0x59, 0x41, 0x02, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// ScheduleClient is the client API for Schedule service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ScheduleClient interface {
+	// Ready returns whether the schedule is ready to be run
+	Ready(ctx context.Context, in *ReadyRequest, opts ...grpc.CallOption) (*ReadyResponse, error)
+	// Run runs the schedule, or one of its scenarios, once
+	Run(ctx context.Context, in *RunRequest, opts ...grpc.CallOption) (*RunResponse, error)
+}
+
+type scheduleClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewScheduleClient(cc *grpc.ClientConn) ScheduleClient {
+	return &scheduleClient{cc}
+}
+
+func (c *scheduleClient) Ready(ctx context.Context, in *ReadyRequest, opts ...grpc.CallOption) (*ReadyResponse, error) {
+	out := new(ReadyResponse)
+	err := c.cc.Invoke(ctx, "/agent.Schedule/Ready", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scheduleClient) Run(ctx context.Context, in *RunRequest, opts ...grpc.CallOption) (*RunResponse, error) {
+	out := new(RunResponse)
+	err := c.cc.Invoke(ctx, "/agent.Schedule/Run", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ScheduleServer is the server API for Schedule service.
+type ScheduleServer interface {
+	// Ready returns whether the schedule is ready to be run
+	Ready(context.Context, *ReadyRequest) (*ReadyResponse, error)
+	// Run runs the schedule, or one of its scenarios, once
+	Run(context.Context, *RunRequest) (*RunResponse, error)
+}
+
+// UnimplementedScheduleServer can be embedded to have forward compatible implementations.
+type UnimplementedScheduleServer struct {
+}
+
+func (*UnimplementedScheduleServer) Ready(ctx context.Context, req *ReadyRequest) (*ReadyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Ready not implemented")
+}
+func (*UnimplementedScheduleServer) Run(ctx context.Context, req *RunRequest) (*RunResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Run not implemented")
+}
+
+func RegisterScheduleServer(s *grpc.Server, srv ScheduleServer) {
+	s.RegisterService(&_Schedule_serviceDesc, srv)
+}
+
+func _Schedule_Ready_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScheduleServer).Ready(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agent.Schedule/Ready",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScheduleServer).Ready(ctx, req.(*ReadyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Schedule_Run_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScheduleServer).Run(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agent.Schedule/Run",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScheduleServer).Run(ctx, req.(*RunRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Schedule_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "agent.Schedule",
+	HandlerType: (*ScheduleServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Ready",
+			Handler:    _Schedule_Ready_Handler,
+		},
+		{
+			MethodName: "Run",
+			Handler:    _Schedule_Run_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "schedule.proto",
+}
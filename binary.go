@@ -0,0 +1,303 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// fetchTimeout is how long a schedule binary has to download in
+	// before the agent gives up on it
+	fetchTimeout = 10 * time.Minute
+)
+
+var (
+	cacheDir  = flag.String("cache", "/var/cache/go-lo", "directory to cache schedule binaries fetched from URLs in")
+	cacheSize = flag.Int64("cache-size", 1<<30, "total size, in bytes, of cached schedule binaries; the least recently used are evicted past this")
+
+	// cacheMutex serialises evictions, so that they don't step
+	// on one another's toes
+	cacheMutex sync.Mutex
+
+	// fetches are the downloads in progress, by digest. Jobs needing
+	// a binary that's already being fetched wait for that download
+	// rather than starting their own, without holding up jobs which
+	// need other binaries
+	fetches      = make(map[string]*fetchCall)
+	fetchesMutex sync.Mutex
+)
+
+// fetchCall is a download in progress, which is done once done
+// is closed
+type fetchCall struct {
+	done chan struct{}
+	err  error
+}
+
+// binary points to a loadtest schedule binary on disk, as
+// built with github.com/go-lo/go-lo. Binaries with a URL are
+// fetched into the cache, and verified against SHA256, first
+type binary struct {
+	Path      string
+	URL       string
+	SHA256    string
+	Signature []byte
+}
+
+// newBinary returns the binary at location, which is either a path
+// on the agent or an http, https or file URL. Binaries fetched from
+// URLs must have a hex encoded SHA-256 digest to be verified against.
+// signature is the binary's base64 encoded detached signature, if it
+// has one
+func newBinary(location, digest, signature string) (b binary, err error) {
+	sig, err := decodeSignature(signature)
+	if err != nil {
+		return
+	}
+
+	// Paths are made absolute, so that they mean the same thing
+	// however the job's workdir is set. Bare names are left to be
+	// looked up in the agent's PATH
+	u, err := url.Parse(location)
+	if err != nil || u.Scheme == "" {
+		path := location
+		if strings.ContainsRune(location, filepath.Separator) {
+			path, err = filepath.Abs(location)
+			if err != nil {
+				return
+			}
+		}
+
+		return binary{Path: path, Signature: sig}, nil
+	}
+
+	switch u.Scheme {
+	case "http", "https", "file":
+	default:
+		return b, fmt.Errorf("binary url scheme %q is not supported", u.Scheme)
+	}
+
+	digest = strings.ToLower(digest)
+
+	d, err := hex.DecodeString(digest)
+	if err != nil || len(d) != sha256.Size {
+		return b, fmt.Errorf("binary url must have a hex encoded sha256 digest")
+	}
+
+	b = binary{
+		URL:       location,
+		SHA256:    digest,
+		Signature: sig,
+	}
+
+	return
+}
+
+// location returns where the binary came from
+func (b binary) location() string {
+	if b.URL != "" {
+		return b.URL
+	}
+
+	return b.Path
+}
+
+// fetch downloads a binary with a URL into the cache, unless it's
+// already there, and points Path at it. Binaries without a URL are
+// left alone
+func (b *binary) fetch() (err error) {
+	if b.URL == "" {
+		return
+	}
+
+	path := filepath.Join(*cacheDir, b.SHA256)
+
+	fetchesMutex.Lock()
+
+	f, ok := fetches[b.SHA256]
+	if !ok {
+		f = &fetchCall{done: make(chan struct{})}
+		fetches[b.SHA256] = f
+	}
+
+	fetchesMutex.Unlock()
+
+	if ok {
+		<-f.done
+	} else {
+		f.err = b.download(path)
+
+		fetchesMutex.Lock()
+		delete(fetches, b.SHA256)
+		fetchesMutex.Unlock()
+
+		close(f.done)
+	}
+
+	if f.err != nil {
+		return f.err
+	}
+
+	b.Path = path
+
+	return
+}
+
+// download fetches the binary into path, unless it's already there.
+// Callers must be the only one fetching the binary's digest
+func (b binary) download(path string) (err error) {
+	// Cached binaries are named by their digest, so whatever is
+	// there is the binary we want. Touching it marks it as used
+	now := time.Now()
+	if os.Chtimes(path, now, now) == nil {
+		return
+	}
+
+	err = os.MkdirAll(*cacheDir, 0755)
+	if err != nil {
+		return
+	}
+
+	r, err := open(b.URL)
+	if err != nil {
+		return
+	}
+
+	defer r.Close()
+
+	f, err := ioutil.TempFile(*cacheDir, ".fetch-")
+	if err != nil {
+		return
+	}
+
+	defer os.Remove(f.Name())
+
+	h := sha256.New()
+
+	// A binary bigger than the whole cache would only be evicted
+	// again, so there's no point reading any more of it than that
+	n, err := io.Copy(io.MultiWriter(f, h), io.LimitReader(r, *cacheSize+1))
+	if err == nil && n > *cacheSize {
+		err = fmt.Errorf("binary %s is larger than the cache size of %d bytes", b.URL, *cacheSize)
+	}
+
+	if err == nil {
+		err = f.Close()
+	} else {
+		f.Close()
+	}
+
+	if err != nil {
+		return
+	}
+
+	digest := hex.EncodeToString(h.Sum(nil))
+	if digest != b.SHA256 {
+		return fmt.Errorf("binary %s has sha256 %s, expected %s", b.URL, digest, b.SHA256)
+	}
+
+	err = os.Chmod(f.Name(), 0755)
+	if err != nil {
+		return
+	}
+
+	err = os.Rename(f.Name(), path)
+	if err != nil {
+		return
+	}
+
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+
+	return evict(path)
+}
+
+// prefetch fetches b into the cache in the background, so that it's
+// there by the time its job starts. Failures are left for the job to
+// run into, and report, when it starts
+func prefetch(b binary) {
+	err := b.fetch()
+	if err != nil {
+		log.Printf("prefetching %s: %+v", b.URL, err)
+	}
+}
+
+// open returns the body of the file at an http, https or file URL
+func open(location string) (r io.ReadCloser, err error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return
+	}
+
+	if u.Scheme == "file" {
+		return os.Open(u.Path)
+	}
+
+	client := &http.Client{
+		Timeout: fetchTimeout,
+	}
+
+	resp, err := client.Get(location)
+	if err != nil {
+		return
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+
+		return nil, fmt.Errorf("fetching %s: %s", location, resp.Status)
+	}
+
+	return resp.Body, nil
+}
+
+// evict removes the least recently used binaries from the cache until
+// it fits in cacheSize, keeping keep, however big it is. Running
+// schedules are unaffected by their binaries being evicted. Callers must
+// hold cacheMutex
+func evict(keep string) (err error) {
+	files, err := ioutil.ReadDir(*cacheDir)
+	if err != nil {
+		return
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().After(files[j].ModTime())
+	})
+
+	var total int64
+	for _, f := range files {
+		path := filepath.Join(*cacheDir, f.Name())
+
+		if f.IsDir() || strings.HasPrefix(f.Name(), ".") {
+			continue
+		}
+
+		total += f.Size()
+		if total <= *cacheSize || path == keep {
+			continue
+		}
+
+		err = os.Remove(path)
+		if err != nil {
+			return
+		}
+
+		total -= f.Size()
+	}
+
+	return
+}
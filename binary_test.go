@@ -0,0 +1,218 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewBinary(t *testing.T) {
+	digest := hex.EncodeToString(make([]byte, sha256.Size))
+
+	for _, test := range []struct {
+		name        string
+		location    string
+		digest      string
+		expectPath  string
+		expectError bool
+	}{
+		{"path", "testdata/dummy-process", "", mustAbs(t, "testdata/dummy-process"), false},
+		{"absolute path", "/bin/true", "", "/bin/true", false},
+		{"name", "true", "", "true", false},
+		{"http url", "http://example.com/schedule", digest, "", false},
+		{"file url", "file:///tmp/schedule", digest, "", false},
+		{"url without digest", "https://example.com/schedule", "", "", true},
+		{"url with short digest", "https://example.com/schedule", "abc123", "", true},
+		{"unsupported scheme", "ftp://example.com/schedule", digest, "", true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			b, err := newBinary(test.location, test.digest, "")
+			if test.expectError && err == nil {
+				t.Errorf("expected error")
+			}
+
+			if !test.expectError && err != nil {
+				t.Errorf("unexpected error %+v", err)
+			}
+
+			if test.expectPath != b.Path {
+				t.Errorf("expected %q, received %q", test.expectPath, b.Path)
+			}
+		})
+	}
+}
+
+func TestBinary_Fetch(t *testing.T) {
+	dir, err := ioutil.TempDir(td, "fetch")
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	cache := filepath.Join(dir, "cache")
+	cacheDir = &cache
+
+	contents := []byte("#!/bin/sh\necho hello\n")
+	sum := sha256.Sum256(contents)
+	digest := hex.EncodeToString(sum[:])
+
+	source := filepath.Join(dir, "schedule")
+
+	err = ioutil.WriteFile(source, contents, 0644)
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if r.URL.Path != "/schedule" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		w.Write(contents)
+	}))
+
+	defer server.Close()
+
+	for _, test := range []struct {
+		name           string
+		url            string
+		digest         string
+		expectRequests int
+		expectError    bool
+	}{
+		{"file url", "file://" + source, digest, 0, false},
+		{"http url, already cached", server.URL + "/schedule", digest, 0, false},
+		{"http url, wrong digest", server.URL + "/schedule", hex.EncodeToString(make([]byte, sha256.Size)), 1, true},
+		{"missing", server.URL + "/nonsuch", digest[1:] + "0", 1, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			requests = 0
+
+			b := binary{URL: test.url, SHA256: test.digest}
+
+			err := b.fetch()
+			if test.expectError && err == nil {
+				t.Errorf("expected error")
+			}
+
+			if !test.expectError && err != nil {
+				t.Errorf("unexpected error %+v", err)
+			}
+
+			if test.expectRequests != requests {
+				t.Errorf("expected %d requests, received %d", test.expectRequests, requests)
+			}
+
+			if test.expectError {
+				return
+			}
+
+			if filepath.Join(cache, digest) != b.Path {
+				t.Errorf("expected %q, received %q", filepath.Join(cache, digest), b.Path)
+			}
+
+			fi, err := os.Stat(b.Path)
+			if err != nil {
+				t.Fatalf("unexpected error %+v", err)
+			}
+
+			if fi.Mode()&0111 == 0 {
+				t.Errorf("expected binary to be executable, received %s", fi.Mode())
+			}
+		})
+	}
+
+	files, _ := ioutil.ReadDir(cache)
+	if len(files) != 1 {
+		t.Errorf("expected only the verified binary to be cached, received %d files", len(files))
+	}
+}
+
+func TestBinary_FetchTooLarge(t *testing.T) {
+	dir, err := ioutil.TempDir(td, "fetch-large")
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	cache := filepath.Join(dir, "cache")
+	cacheDir = &cache
+
+	size := int64(10)
+	defaultSize := *cacheSize
+
+	cacheSize = &size
+	defer func() {
+		cacheSize = &defaultSize
+	}()
+
+	contents := make([]byte, 11)
+	sum := sha256.Sum256(contents)
+
+	source := filepath.Join(dir, "schedule")
+
+	err = ioutil.WriteFile(source, contents, 0644)
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	b := binary{URL: "file://" + source, SHA256: hex.EncodeToString(sum[:])}
+
+	err = b.fetch()
+	if err == nil {
+		t.Errorf("expected error")
+	}
+
+	files, _ := ioutil.ReadDir(cache)
+	if len(files) != 0 {
+		t.Errorf("expected nothing to be cached, received %d files", len(files))
+	}
+}
+
+func TestEvict(t *testing.T) {
+	dir, err := ioutil.TempDir(td, "evict")
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	cacheDir = &dir
+
+	size := int64(25)
+	defaultSize := *cacheSize
+
+	cacheSize = &size
+	defer func() {
+		cacheSize = &defaultSize
+	}()
+
+	// Each binary is 10 bytes, and was used a minute after the last
+	start := time.Now().Add(-time.Hour)
+	for i, name := range []string{"a", "b", "c", "d"} {
+		path := filepath.Join(dir, name)
+		ioutil.WriteFile(path, make([]byte, 10), 0755)
+
+		used := start.Add(time.Duration(i) * time.Minute)
+		os.Chtimes(path, used, used)
+	}
+
+	err = evict(filepath.Join(dir, "a"))
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	for name, expect := range map[string]bool{"a": true, "b": false, "c": true, "d": true} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if expect != (err == nil) {
+			t.Errorf("%s: expected cached %v, received %v", name, expect, err == nil)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"math"
+	"sync"
+)
+
+const (
+	// subscriberBuffer is the number of outputs a subscriber can fall
+	// behind by before outputs are dropped
+	subscriberBuffer = 1024
+)
+
+// broadcaster fans the output of a job out to any number of subscribers.
+// Publishing never blocks: a subscriber which falls too far behind misses
+// outputs rather than slowing the job down
+type broadcaster struct {
+	mutex       sync.Mutex
+	subscribers map[*subscriber]bool
+	closed      bool
+}
+
+// subscriber receives one in every n outputs published to a
+// broadcaster. c is closed when the broadcaster is
+type subscriber struct {
+	c       chan Output
+	every   int
+	seen    int
+	dropped int
+}
+
+func newBroadcaster() *broadcaster {
+	return &broadcaster{
+		subscribers: make(map[*subscriber]bool),
+	}
+}
+
+// subscribe returns a subscriber receiving sample (0, 1] of published
+// outputs, spread evenly across the stream, such that a sample of 0.25
+// receives every fourth output. A sample of 0 receives everything
+func (b *broadcaster) subscribe(sample float64) (s *subscriber) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if sample <= 0 || sample > 1 {
+		sample = 1
+	}
+
+	s = &subscriber{
+		c:     make(chan Output, subscriberBuffer),
+		every: int(math.Round(1 / sample)),
+	}
+
+	if b.closed {
+		close(s.c)
+
+		return
+	}
+
+	b.subscribers[s] = true
+
+	return
+}
+
+func (b *broadcaster) unsubscribe(s *subscriber) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.subscribers[s] {
+		delete(b.subscribers, s)
+		close(s.c)
+	}
+}
+
+func (b *broadcaster) publish(o Output) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for s := range b.subscribers {
+		s.seen++
+		if s.seen%s.every != 0 {
+			continue
+		}
+
+		select {
+		case s.c <- o:
+		default:
+			s.dropped++
+		}
+	}
+}
+
+// close stops the broadcaster, closing each subscriber
+func (b *broadcaster) close() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	b.closed = true
+	for s := range b.subscribers {
+		delete(b.subscribers, s)
+		close(s.c)
+	}
+}
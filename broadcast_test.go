@@ -0,0 +1,80 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestBroadcaster_Sample(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		sample float64
+		expect int
+	}{
+		{"everything", 1, 100},
+		{"unset", 0, 100},
+		{"out of range", 2, 100},
+		{"every tenth", 0.1, 10},
+		{"every other", 0.5, 50},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			b := newBroadcaster()
+			s := b.subscribe(test.sample)
+
+			for i := 0; i < 100; i++ {
+				b.publish(Output{})
+			}
+
+			b.close()
+
+			var received int
+			for range s.c {
+				received++
+			}
+
+			if test.expect != received {
+				t.Errorf("expected %d outputs, received %d", test.expect, received)
+			}
+		})
+	}
+}
+
+func TestBroadcaster_SlowSubscriber(t *testing.T) {
+	b := newBroadcaster()
+
+	slow := b.subscribe(1)
+	fast := b.subscribe(1)
+
+	done := make(chan bool)
+	go func() {
+		for range fast.c {
+		}
+
+		done <- true
+	}()
+
+	// slow never reads, and so this would block forever were
+	// subscribers able to hold up publishing
+	for i := 0; i < subscriberBuffer*2; i++ {
+		b.publish(Output{})
+	}
+
+	b.close()
+	<-done
+
+	if subscriberBuffer != slow.dropped {
+		t.Errorf("expected %d dropped outputs, received %d", subscriberBuffer, slow.dropped)
+	}
+}
+
+func TestBroadcaster_Closed(t *testing.T) {
+	b := newBroadcaster()
+	b.close()
+
+	s := b.subscribe(1)
+	if _, ok := <-s.c; ok {
+		t.Errorf("expected subscriber to be closed")
+	}
+
+	// unsubscribing a closed subscriber shouldn't panic
+	b.unsubscribe(s)
+}
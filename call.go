@@ -0,0 +1,83 @@
+package main
+
+import (
+	"github.com/go-lo/agent/agent"
+)
+
+// Call is the argument of every call the agent makes to a schedule,
+// telling it which user and iteration the call is for, so that it can,
+// say, log in as a different account for each user.
+//
+// Schedules which take a golo.NullArg still work: net/rpc's gob encoding
+// decodes a Call into a struct with none of its fields without complaint.
+// Equally, schedules can take a struct with just the fields they need
+type Call struct {
+	// User is the index of the virtual user making the call, and
+	// Iteration the number of calls that user has made before it.
+	// Jobs with a Rate have no users; User is always 0, and Iteration
+	// counts every call
+	User      int
+	Iteration int64
+
+	// Job and RunID are the Name and ID of the job
+	Job   string
+	RunID string
+
+	Scenario string
+	Params   map[string]string
+
+	// Record is from the job's Feeder, if it has one
+	Record map[string]string
+
+	// scenario is the Scenario named by Scenario
+	scenario Scenario
+}
+
+// newCall returns the Call for user's iteration'th call, in a scenario
+// picked from the job's, and with the next record from its feeder. It
+// returns errExhausted if the feeder has no record to give
+func (j *Job) newCall(user int, iteration int64) (c Call, err error) {
+	record, err := j.feeder.record(user)
+	if err != nil {
+		return
+	}
+
+	c = j.newProbe()
+	c.User = user
+	c.Iteration = iteration
+	c.Record = record
+
+	return
+}
+
+// newProbe returns a Call for checking whether the schedule is ready.
+// It has no record, so that probing doesn't use up the feeder's
+func (j *Job) newProbe() Call {
+	s := j.scenario()
+
+	return Call{
+		Job:      j.Name,
+		RunID:    j.ID,
+		Scenario: s.Name,
+		Params:   j.Params,
+		scenario: s,
+	}
+}
+
+// method is the method on the schedule c calls
+func (c Call) method() string {
+	return c.scenario.method()
+}
+
+// proto converts c into the RunRequest gRPC schedules take
+func (c Call) proto() *agent.RunRequest {
+	return &agent.RunRequest{
+		Scenario:  c.Scenario,
+		User:      uint32(c.User),
+		Iteration: uint64(c.Iteration),
+		Job:       c.Job,
+		RunId:     c.RunID,
+		Params:    c.Params,
+		Record:    c.Record,
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/rpc"
+	"reflect"
+	"testing"
+
+	"github.com/go-lo/agent/agent"
+	"github.com/go-lo/go-lo"
+	"google.golang.org/grpc"
+)
+
+// PartialCall is a schedule's own argument type, with only some
+// of the fields of a Call. net/rpc only serves exported argument types
+type PartialCall struct {
+	User      int
+	Iteration int64
+	Params    map[string]string
+}
+
+type callServer struct {
+	received chan PartialCall
+}
+
+func (s callServer) Run(c *PartialCall, _ *golo.NullArg) error {
+	s.received <- *c
+
+	return nil
+}
+
+type callGRPCServer struct {
+	dummyScheduleServer
+
+	received chan *agent.RunRequest
+}
+
+func (s callGRPCServer) Run(_ context.Context, r *agent.RunRequest) (*agent.RunResponse, error) {
+	s.received <- r
+
+	return &agent.RunResponse{}, nil
+}
+
+func TestJob_NewCall(t *testing.T) {
+	RPCCommand = "Server.Run"
+
+	j := Job{
+		ID:        "abc123",
+		Name:      "test",
+		Params:    map[string]string{"region": "eu"},
+		Scenarios: []Scenario{{Name: "search", Method: "Server.Search"}},
+	}
+
+	c, err := j.newCall(4, 12)
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	expect := Call{
+		User:      4,
+		Iteration: 12,
+		Job:       "test",
+		RunID:     "abc123",
+		Scenario:  "search",
+		Params:    map[string]string{"region": "eu"},
+		scenario:  Scenario{Name: "search", Method: "Server.Search"},
+	}
+
+	if !reflect.DeepEqual(expect, c) {
+		t.Errorf("expected %+v, received %+v", expect, c)
+	}
+
+	if c.method() != "Server.Search" {
+		t.Errorf("expected %q, received %q", "Server.Search", c.method())
+	}
+}
+
+func TestJob_Call(t *testing.T) {
+	params := map[string]string{"region": "eu"}
+
+	t.Run("rpc", func(t *testing.T) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("unexpected error %+v", err)
+		}
+
+		defer l.Close()
+
+		received := make(chan PartialCall, 1)
+
+		s := rpc.NewServer()
+		s.RegisterName("Server", callServer{received})
+		s.Register(&DummyServer{})
+		go s.Accept(l)
+
+		c, err := rpc.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatalf("unexpected error %+v", err)
+		}
+
+		defer c.Close()
+
+		j := Job{Name: "test", Params: params, service: c, setup: true}
+
+		t.Run("schedule taking a partial call", func(t *testing.T) {
+			RPCCommand = "Server.Run"
+
+			err := j.call(3, 7)
+			if err != nil {
+				t.Fatalf("unexpected error %+v", err)
+			}
+
+			expect := PartialCall{User: 3, Iteration: 7, Params: params}
+			if r := <-received; !reflect.DeepEqual(expect, r) {
+				t.Errorf("expected %+v, received %+v", expect, r)
+			}
+		})
+
+		t.Run("schedule taking a NullArg", func(t *testing.T) {
+			RPCCommand = "DummyServer.Run"
+
+			err := j.call(3, 7)
+			if err != nil {
+				t.Errorf("unexpected error %+v", err)
+			}
+		})
+	})
+
+	t.Run("grpc", func(t *testing.T) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("unexpected error %+v", err)
+		}
+
+		received := make(chan *agent.RunRequest, 1)
+
+		s := grpc.NewServer()
+		agent.RegisterScheduleServer(s, callGRPCServer{received: received})
+
+		go s.Serve(l)
+		defer s.Stop()
+
+		c, err := dialGRPC(func() (net.Conn, error) {
+			return net.Dial("tcp", l.Addr().String())
+		})
+
+		if err != nil {
+			t.Fatalf("unexpected error %+v", err)
+		}
+
+		defer c.Close()
+
+		j := Job{
+			ID:        "abc123",
+			Name:      "test",
+			Params:    params,
+			Transport: TransportGRPC,
+			Scenarios: []Scenario{{Name: "browse"}},
+			service:   c,
+			setup:     true,
+		}
+
+		err = j.call(3, 7)
+		if err != nil {
+			t.Fatalf("unexpected error %+v", err)
+		}
+
+		r := <-received
+
+		expect := &agent.RunRequest{Scenario: "browse", User: 3, Iteration: 7, Job: "test", RunId: "abc123", Params: params}
+		if expect.String() != r.String() {
+			t.Errorf("expected %q, received %q", expect, r)
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// cpuPeriod is the period, in microseconds, over which
+	// cgroups measure cpu quotas
+	cpuPeriod = 100000
+)
+
+var (
+	cgroupRoot = flag.String("cgroup", "/sys/fs/cgroup/go-lo", "cgroup v2 group to create schedule cgroups under, when jobs have limits")
+)
+
+// Limits cap the resources a schedule can use, so that a badly written
+// schedule can't starve the agent. CPU is in cores, Memory in bytes.
+// Unset limits aren't applied
+type Limits struct {
+	CPU    float64 `json:"cpu"`
+	Memory int64   `json:"memory"`
+	Pids   int64   `json:"pids"`
+}
+
+// set returns whether any limits are set
+func (l Limits) set() bool {
+	return l.CPU > 0 || l.Memory > 0 || l.Pids > 0
+}
+
+// cgroup is a cgroup v2 group a schedule runs in. A nil cgroup is
+// valid, and does nothing, which is what schedules without limits (or
+// on agents where cgroups aren't writable) get
+type cgroup struct {
+	path string
+
+	// dir is the cgroup's directory, held open so that schedules can
+	// be started in it. See attach
+	dir *os.File
+}
+
+// newCgroup creates a cgroup called name under cgroupRoot, with limits
+// applied
+func newCgroup(name string, l Limits) (c *cgroup, err error) {
+	err = os.MkdirAll(*cgroupRoot, 0755)
+	if err != nil {
+		return
+	}
+
+	// Controllers have to be enabled for children by their parent before
+	// children can set limits with them
+	err = writeCgroupFile(*cgroupRoot, "cgroup.subtree_control", "+cpu +memory +pids")
+	if err != nil {
+		return
+	}
+
+	c = &cgroup{
+		path: filepath.Join(*cgroupRoot, name),
+	}
+
+	err = os.Mkdir(c.path, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	if l.CPU > 0 {
+		err = writeCgroupFile(c.path, "cpu.max", fmt.Sprintf("%d %d", int64(l.CPU*cpuPeriod), cpuPeriod))
+	}
+
+	if err == nil && l.Memory > 0 {
+		err = writeCgroupFile(c.path, "memory.max", strconv.FormatInt(l.Memory, 10))
+	}
+
+	if err == nil && l.Pids > 0 {
+		err = writeCgroupFile(c.path, "pids.max", strconv.FormatInt(l.Pids, 10))
+	}
+
+	if err == nil {
+		c.dir, err = os.Open(c.path)
+	}
+
+	if err != nil {
+		c.remove()
+
+		return nil, err
+	}
+
+	return
+}
+
+// add moves a process into the cgroup. Anything the process starts
+// from then on is in the cgroup too. It's for toolchains which can't
+// start processes in a cgroup; see attach
+func (c *cgroup) add(pid int) error {
+	if c == nil {
+		return nil
+	}
+
+	return writeCgroupFile(c.path, "cgroup.procs", strconv.Itoa(pid))
+}
+
+// oomKilled returns whether the kernel has killed anything in
+// the cgroup for going over its memory limit
+func (c *cgroup) oomKilled() bool {
+	if c == nil {
+		return false
+	}
+
+	f, err := os.Open(filepath.Join(c.path, "memory.events"))
+	if err != nil {
+		return false
+	}
+
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "oom_kill" {
+			return fields[1] != "0"
+		}
+	}
+
+	return false
+}
+
+// kill kills everything in the cgroup, including anything which has
+// left the schedule's process group. Kernels without cgroup.kill, from
+// before 5.14, are left to the process group being killed
+func (c *cgroup) kill() error {
+	if c == nil {
+		return nil
+	}
+
+	_, err := os.Stat(filepath.Join(c.path, "cgroup.kill"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	return writeCgroupFile(c.path, "cgroup.kill", "1")
+}
+
+// remove deletes the cgroup, which only works once everything
+// in it has exited
+func (c *cgroup) remove() error {
+	if c == nil {
+		return nil
+	}
+
+	if c.dir != nil {
+		c.dir.Close()
+	}
+
+	return os.RemoveAll(c.path)
+}
+
+func writeCgroupFile(dir, file, value string) error {
+	return ioutil.WriteFile(filepath.Join(dir, file), []byte(value), 0644)
+}
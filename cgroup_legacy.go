@@ -0,0 +1,18 @@
+//go:build linux && !go1.20
+// +build linux,!go1.20
+
+package main
+
+import (
+	"syscall"
+)
+
+// attachedAtStart is whether attach starts processes in their cgroup,
+// or leaves them to be added once they've started
+const attachedAtStart = false
+
+// attach does nothing: toolchains before go 1.20 can't start processes
+// in a cgroup, so they're added to it once started instead
+func (c *cgroup) attach(*syscall.SysProcAttr) error {
+	return nil
+}
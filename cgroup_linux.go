@@ -0,0 +1,26 @@
+//go:build linux && go1.20
+// +build linux,go1.20
+
+package main
+
+import (
+	"syscall"
+)
+
+// attachedAtStart is whether attach starts processes in their cgroup,
+// or leaves them to be added once they've started
+const attachedAtStart = true
+
+// attach has a process started with attr start inside the cgroup, so
+// that its limits apply to everything the process runs from the outset.
+// A nil cgroup attaches nothing
+func (c *cgroup) attach(attr *syscall.SysProcAttr) error {
+	if c == nil {
+		return nil
+	}
+
+	attr.UseCgroupFD = true
+	attr.CgroupFD = int(c.dir.Fd())
+
+	return nil
+}
@@ -0,0 +1,51 @@
+//go:build go1.20
+// +build go1.20
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestCgroup_Attach(t *testing.T) {
+	root := filepath.Join(td, "cgroup-attach")
+	defer os.RemoveAll(root)
+
+	cgroupRoot = &root
+
+	c, err := newCgroup("test", Limits{Pids: 10})
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	defer c.remove()
+
+	attr := new(syscall.SysProcAttr)
+
+	err = c.attach(attr)
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	if !attr.UseCgroupFD || attr.CgroupFD != int(c.dir.Fd()) {
+		t.Errorf("expected process to start in the cgroup")
+	}
+
+	t.Run("no cgroup", func(t *testing.T) {
+		var c *cgroup
+
+		attr := new(syscall.SysProcAttr)
+
+		err := c.attach(attr)
+		if err != nil {
+			t.Fatalf("unexpected error %+v", err)
+		}
+
+		if attr.UseCgroupFD {
+			t.Errorf("expected process not to start in a cgroup")
+		}
+	})
+}
@@ -0,0 +1,23 @@
+//go:build !linux
+// +build !linux
+
+package main
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// attachedAtStart is whether attach starts processes in their cgroup,
+// or leaves them to be added once they've started
+const attachedAtStart = false
+
+// attach fails outside of linux, which has no cgroups to start
+// processes in. A nil cgroup attaches nothing
+func (c *cgroup) attach(*syscall.SysProcAttr) error {
+	if c == nil {
+		return nil
+	}
+
+	return fmt.Errorf("cgroups are only available on linux")
+}
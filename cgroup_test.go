@@ -0,0 +1,124 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewCgroup(t *testing.T) {
+	root := filepath.Join(td, "cgroup")
+	defer os.RemoveAll(root)
+
+	// A temporary directory stands in for a cgroup v2 hierarchy here;
+	// the files written are the same either way
+	cgroupRoot = &root
+
+	c, err := newCgroup("test", Limits{CPU: 1.5, Memory: 1 << 20})
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	defer c.remove()
+
+	for _, test := range []struct {
+		dir    string
+		file   string
+		expect string
+	}{
+		{root, "cgroup.subtree_control", "+cpu +memory +pids"},
+		{c.path, "cpu.max", "150000 100000"},
+		{c.path, "memory.max", "1048576"},
+	} {
+		t.Run(test.file, func(t *testing.T) {
+			b, err := ioutil.ReadFile(filepath.Join(test.dir, test.file))
+			if err != nil {
+				t.Fatalf("unexpected error %+v", err)
+			}
+
+			if test.expect != string(b) {
+				t.Errorf("expected %q, received %q", test.expect, string(b))
+			}
+		})
+	}
+
+	t.Run("unset limits aren't written", func(t *testing.T) {
+		_, err := os.Stat(filepath.Join(c.path, "pids.max"))
+		if !os.IsNotExist(err) {
+			t.Errorf("expected pids.max not to exist")
+		}
+	})
+
+	t.Run("add", func(t *testing.T) {
+		err := c.add(1234)
+		if err != nil {
+			t.Fatalf("unexpected error %+v", err)
+		}
+
+		b, _ := ioutil.ReadFile(filepath.Join(c.path, "cgroup.procs"))
+		if "1234" != string(b) {
+			t.Errorf("expected %q, received %q", "1234", string(b))
+		}
+	})
+
+	t.Run("kill without cgroup.kill", func(t *testing.T) {
+		err := c.kill()
+		if err != nil {
+			t.Fatalf("unexpected error %+v", err)
+		}
+
+		_, err = os.Stat(filepath.Join(c.path, "cgroup.kill"))
+		if !os.IsNotExist(err) {
+			t.Errorf("expected cgroup.kill not to be created")
+		}
+	})
+
+	t.Run("kill", func(t *testing.T) {
+		ioutil.WriteFile(filepath.Join(c.path, "cgroup.kill"), nil, 0644)
+
+		err := c.kill()
+		if err != nil {
+			t.Fatalf("unexpected error %+v", err)
+		}
+
+		b, _ := ioutil.ReadFile(filepath.Join(c.path, "cgroup.kill"))
+		if "1" != string(b) {
+			t.Errorf("expected %q, received %q", "1", string(b))
+		}
+	})
+}
+
+func TestNewCgroup_Unwritable(t *testing.T) {
+	root := "/proc/go-lo"
+	cgroupRoot = &root
+
+	_, err := newCgroup("test", Limits{Pids: 10})
+	if err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestCgroup_OOMKilled(t *testing.T) {
+	dir, _ := ioutil.TempDir(td, "oom")
+	defer os.RemoveAll(dir)
+
+	for _, test := range []struct {
+		name   string
+		c      *cgroup
+		events string
+		expect bool
+	}{
+		{"oom killed", &cgroup{path: dir}, "low 0\nhigh 0\nmax 3\noom 1\noom_kill 1\n", true},
+		{"not oom killed", &cgroup{path: dir}, "low 0\nhigh 0\nmax 0\noom 0\noom_kill 0\n", false},
+		{"no cgroup", nil, "", false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			ioutil.WriteFile(filepath.Join(dir, "memory.events"), []byte(test.events), 0644)
+
+			if test.expect != test.c.oomKilled() {
+				t.Errorf("expected %v, received %v", test.expect, !test.expect)
+			}
+		})
+	}
+}
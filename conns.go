@@ -0,0 +1,101 @@
+package main
+
+import (
+	"sync/atomic"
+)
+
+const (
+	// BalanceRoundRobin spreads calls across a job's connections
+	// in turn
+	BalanceRoundRobin = "round-robin"
+
+	// BalanceLeastOutstanding sends each call down whichever of a job's
+	// connections is waiting on the fewest calls, which copes better
+	// with some calls being much slower than others
+	BalanceLeastOutstanding = "least-outstanding"
+)
+
+// connPool spreads a job's calls across several RPC clients, each with
+// its own connection to the schedule, so that a single connection (and
+// its codec) doesn't limit how hard a job can push. It counts the calls
+// made on each connection
+type connPool struct {
+	balance     string
+	clients     []rpcClient
+	outstanding []int64
+	calls       []int64
+	next        uint64
+}
+
+func newConnPool(clients []rpcClient, balance string) *connPool {
+	return &connPool{
+		balance:     balance,
+		clients:     clients,
+		outstanding: make([]int64, len(clients)),
+		calls:       make([]int64, len(clients)),
+	}
+}
+
+// Call makes a call on one of the pool's clients
+func (p *connPool) Call(method string, args interface{}, reply interface{}) error {
+	i := p.pick()
+
+	atomic.AddInt64(&p.calls[i], 1)
+	atomic.AddInt64(&p.outstanding[i], 1)
+	defer atomic.AddInt64(&p.outstanding[i], -1)
+
+	return p.clients[i].Call(method, args, reply)
+}
+
+// Close closes every client in the pool, returning the first error
+// any of them returns
+func (p *connPool) Close() (err error) {
+	for _, c := range p.clients {
+		cerr := c.Close()
+		if err == nil {
+			err = cerr
+		}
+	}
+
+	return
+}
+
+// pick returns the index of the client the next call should use
+func (p *connPool) pick() (i int) {
+	n := len(p.clients)
+
+	// Starting from the next client in turn means that least-outstanding
+	// spreads calls evenly when connections are equally busy
+	start := int((atomic.AddUint64(&p.next, 1) - 1) % uint64(n))
+	if p.balance != BalanceLeastOutstanding {
+		return start
+	}
+
+	i = start
+	least := atomic.LoadInt64(&p.outstanding[i])
+
+	for offset := 1; offset < n && least > 0; offset++ {
+		c := (start + offset) % n
+
+		o := atomic.LoadInt64(&p.outstanding[c])
+		if o < least {
+			i, least = c, o
+		}
+	}
+
+	return
+}
+
+// counts returns the number of calls made on each connection
+func (p *connPool) counts() (c []uint64) {
+	if p == nil {
+		return nil
+	}
+
+	c = make([]uint64, len(p.calls))
+	for i := range p.calls {
+		c[i] = uint64(atomic.LoadInt64(&p.calls[i]))
+	}
+
+	return
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/rpc"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-lo/go-lo"
+)
+
+func TestConnPool_RoundRobin(t *testing.T) {
+	p := newConnPool([]rpcClient{dummyRPCClient{}, dummyRPCClient{}, dummyRPCClient{}}, BalanceRoundRobin)
+
+	for i := 0; i < 30; i++ {
+		p.Call("Server.Run", nil, nil)
+	}
+
+	for i, c := range p.counts() {
+		if c != 10 {
+			t.Errorf("connection %d: expected %d calls, received %d", i, 10, c)
+		}
+	}
+}
+
+func TestConnPool_LeastOutstanding(t *testing.T) {
+	slow := new(int64)
+	fast := new(int64)
+
+	// One connection is stuck on slow calls; least-outstanding should
+	// send everything else down the other
+	p := newConnPool([]rpcClient{
+		countingRPCClient{calls: slow, delay: 500 * time.Millisecond},
+		countingRPCClient{calls: fast},
+	}, BalanceLeastOutstanding)
+
+	wg := new(sync.WaitGroup)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		p.Call("Server.Run", nil, nil)
+	}()
+
+	// give the first call a chance to start
+	time.Sleep(50 * time.Millisecond)
+
+	for i := 0; i < 10; i++ {
+		p.Call("Server.Run", nil, nil)
+	}
+
+	wg.Wait()
+
+	expect := []uint64{1, 10}
+	if fmt.Sprint(expect) != fmt.Sprint(p.counts()) {
+		t.Errorf("expected %v, received %v", expect, p.counts())
+	}
+}
+
+func TestConnPool_Counts(t *testing.T) {
+	var p *connPool
+
+	if p.counts() != nil {
+		t.Errorf("expected no counts without a pool")
+	}
+}
+
+func TestJob_InitialiseRPC_Connections(t *testing.T) {
+	expoBackoff.MaxElapsedTime = time.Millisecond
+
+	l, err := net.Listen("tcp", golo.RPCAddr)
+	if err != nil {
+		t.Fatalf("unexpected error starting server: %+v", err)
+	}
+
+	defer l.Close()
+
+	var (
+		mutex    sync.Mutex
+		accepted int
+	)
+
+	s := rpc.NewServer()
+	s.Register(&DummyServer{})
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			mutex.Lock()
+			accepted++
+			mutex.Unlock()
+
+			go s.ServeConn(conn)
+		}
+	}()
+
+	RPCCommand = "DummyServer.Run"
+
+	j := Job{Connections: 4}
+
+	err = j.initialiseRPC()
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	defer j.service.Close()
+
+	for i := 0; i < 8; i++ {
+		j.service.Call(RPCCommand, &golo.NullArg{}, &golo.NullArg{})
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if accepted != 4 {
+		t.Errorf("expected %d connections, received %d", 4, accepted)
+	}
+
+	// The first call is the one which checks the schedule is ready
+	expect := []uint64{3, 2, 2, 2}
+	if fmt.Sprint(expect) != fmt.Sprint(j.conns.counts()) {
+		t.Errorf("expected %v, received %v", expect, j.conns.counts())
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// secretPrefix marks an env value as the name of a file in
+	// secretsDir, rather than a value in itself
+	secretPrefix = "secret:"
+)
+
+var (
+	inheritEnv = flag.String("inherit-env", "PATH,HOME,TZ", "comma separated environment variables local schedules inherit from the agent")
+	secretsDir = flag.String("secrets", "/etc/go-lo/secrets", "directory of files which job env values can reference as secret:<file>")
+)
+
+// environment returns the job's Env as KEY=value pairs, sorted by key,
+// with any secrets read in
+func (j *Job) environment() (env []string, err error) {
+	keys := make([]string, 0, len(j.Env))
+	for k := range j.Env {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	env = make([]string, len(keys))
+	for i, k := range keys {
+		if k == "" || strings.Contains(k, "=") {
+			return nil, fmt.Errorf("env var name %q is invalid", k)
+		}
+
+		// Signed binaries could otherwise be made to load, or
+		// behave like, something which isn't
+		if len(trustedKeys) > 0 && loaderEnv(k) {
+			return nil, fmt.Errorf("env var %s can't be set on an agent which only runs signed binaries", k)
+		}
+
+		v := j.Env[k]
+		if strings.HasPrefix(v, secretPrefix) {
+			v, err = secret(strings.TrimPrefix(v, secretPrefix))
+			if err != nil {
+				return nil, fmt.Errorf("env var %s: %+v", k, err)
+			}
+		}
+
+		env[i] = k + "=" + v
+	}
+
+	return
+}
+
+// loaderEnv returns whether the env var called name changes what code
+// a process loads, or how the go runtime behaves
+func loaderEnv(name string) bool {
+	switch {
+	case strings.HasPrefix(name, "LD_"), strings.HasPrefix(name, "DYLD_"):
+		return true
+	}
+
+	switch name {
+	case "GODEBUG", "GOTRACEBACK", "GOGC", "GOMAXPROCS", "GOMEMLIMIT":
+		return true
+	}
+
+	return false
+}
+
+// inherited returns the variables, from the agent's own environment,
+// which inheritEnv allows schedules to inherit
+func inherited() (env []string) {
+	env = make([]string, 0)
+
+	for _, k := range strings.Split(*inheritEnv, ",") {
+		k = strings.TrimSpace(k)
+		if k == "" {
+			continue
+		}
+
+		if v, ok := os.LookupEnv(k); ok {
+			env = append(env, k+"="+v)
+		}
+	}
+
+	return
+}
+
+// secret returns the contents of the file called name in secretsDir,
+// without a trailing newline
+func secret(name string) (s string, err error) {
+	if name == "" || name != filepath.Base(name) || name == "." || name == ".." {
+		return "", fmt.Errorf("secret name %q is invalid", name)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(*secretsDir, name))
+	if err != nil {
+		return "", fmt.Errorf("reading secret %s: %+v", name, err)
+	}
+
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
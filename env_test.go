@@ -0,0 +1,155 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJob_Environment(t *testing.T) {
+	dir, err := ioutil.TempDir(td, "secrets")
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	secretsDir = &dir
+	ioutil.WriteFile(filepath.Join(dir, "api-token"), []byte("s3cr3t\n"), 0600)
+
+	for _, test := range []struct {
+		name        string
+		env         map[string]string
+		expect      []string
+		expectError bool
+	}{
+		{"no env", nil, []string{}, false},
+		{"values", map[string]string{"TARGET": "example.com", "DEBUG": "1"}, []string{"DEBUG=1", "TARGET=example.com"}, false},
+		{"secret", map[string]string{"TOKEN": "secret:api-token"}, []string{"TOKEN=s3cr3t"}, false},
+		{"missing secret", map[string]string{"TOKEN": "secret:nonsuch"}, nil, true},
+		{"secret outside the secrets directory", map[string]string{"TOKEN": "secret:../api-token"}, nil, true},
+		{"invalid name", map[string]string{"A=B": "c"}, nil, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			env, err := (&Job{Env: test.env}).environment()
+			if test.expectError && err == nil {
+				t.Errorf("expected error")
+			}
+
+			if !test.expectError && err != nil {
+				t.Errorf("unexpected error %+v", err)
+			}
+
+			if strings.Join(test.expect, ",") != strings.Join(env, ",") {
+				t.Errorf("expected %q, received %q", test.expect, env)
+			}
+		})
+	}
+}
+
+func TestJob_Environment_Signed(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		keys        []ed25519.PublicKey
+		env         map[string]string
+		expectError bool
+	}{
+		{"loader var, unsigned agent", nil, map[string]string{"LD_PRELOAD": "/tmp/evil.so"}, false},
+		{"loader var", make([]ed25519.PublicKey, 1), map[string]string{"LD_PRELOAD": "/tmp/evil.so"}, true},
+		{"go runtime var", make([]ed25519.PublicKey, 1), map[string]string{"GODEBUG": "x=1"}, true},
+		{"other var", make([]ed25519.PublicKey, 1), map[string]string{"TARGET": "example.com"}, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			trustedKeys = test.keys
+			defer func() {
+				trustedKeys = nil
+			}()
+
+			_, err := (&Job{Env: test.env}).environment()
+			if test.expectError && err == nil {
+				t.Errorf("expected error")
+			}
+
+			if !test.expectError && err != nil {
+				t.Errorf("unexpected error %+v", err)
+			}
+		})
+	}
+}
+
+func TestInherited(t *testing.T) {
+	os.Setenv("GOLO_INHERITED", "yes")
+	os.Setenv("GOLO_NOT_INHERITED", "no")
+
+	allow := "PATH, GOLO_INHERITED,GOLO_UNSET"
+	defaultAllow := *inheritEnv
+
+	inheritEnv = &allow
+	defer func() {
+		inheritEnv = &defaultAllow
+	}()
+
+	expect := fmt.Sprintf("PATH=%s,GOLO_INHERITED=yes", os.Getenv("PATH"))
+	if received := strings.Join(inherited(), ","); expect != received {
+		t.Errorf("expected %q, received %q", expect, received)
+	}
+}
+
+func TestLocalRuntime_Inputs(t *testing.T) {
+	dir, err := ioutil.TempDir(td, "inputs")
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	secretsDir = &dir
+	ioutil.WriteFile(filepath.Join(dir, "api-token"), []byte("s3cr3t"), 0600)
+
+	out := filepath.Join(dir, "out")
+	script := filepath.Join(dir, "schedule.sh")
+
+	err = ioutil.WriteFile(script, []byte(fmt.Sprintf("#!/bin/sh\necho \"$@ $TARGET $TOKEN $GOLO_NOT_INHERITED $(pwd)\" > %s\n", out)), 0755)
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	os.Setenv("GOLO_NOT_INHERITED", "leaked")
+
+	j := &Job{
+		Args:    []string{"-v", "run"},
+		Env:     map[string]string{"TARGET": "example.com", "TOKEN": "secret:api-token"},
+		Workdir: dir,
+		bin:     binary{Path: script},
+	}
+
+	r := new(localRuntime)
+
+	err = r.start(j)
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	go ioutil.ReadAll(r.stdout())
+	go ioutil.ReadAll(r.stderr())
+
+	done := make(chan struct{})
+	go func() {
+		r.wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("schedule never exited")
+	}
+
+	b, _ := ioutil.ReadFile(out)
+
+	expect := fmt.Sprintf("-v run example.com s3cr3t  %s", dir)
+	if received := strings.TrimSpace(string(b)); expect != received {
+		t.Errorf("expected %q, received %q", expect, received)
+	}
+}
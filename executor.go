@@ -0,0 +1,134 @@
+package main
+
+import (
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// users runs the job as a closed model, starting a user whenever the pool
+// has room for one. Each user calls the schedule once per j.Pacing, plus
+// any think time, for as long as the job runs or until the pool shrinks
+func (j *Job) users() {
+	for {
+		id, ok := j.pool.acquire()
+		if !ok {
+			return
+		}
+
+		go j.user(id)
+	}
+}
+
+// user is a single virtual user. A user makes a call, waits out the rest
+// of the pacing interval (if the call took less time than that) and then
+// its think time, before making another. Users stop, for good, when the
+// job's feeder has no records left to give them
+func (j *Job) user(id int) {
+	for iteration := int64(0); ; iteration++ {
+		if j.isComplete() {
+			j.pool.release(id)
+
+			return
+		}
+
+		if j.pool.retire(id) {
+			return
+		}
+
+		start := time.Now()
+		if !j.request(id, iteration) {
+			j.pool.stop()
+
+			return
+		}
+
+		if !j.pool.sleep(j.pause(time.Since(start))) {
+			j.pool.release(id)
+
+			return
+		}
+	}
+}
+
+// pause returns how long a user waits after a call which took elapsed:
+// whatever is left of the pacing interval, if anything, and then its
+// think time. A call which overruns the pacing interval doesn't eat
+// into the think time
+func (j *Job) pause(elapsed time.Duration) time.Duration {
+	rest := time.Duration(j.Pacing)*time.Millisecond - elapsed
+	if rest < 0 {
+		rest = 0
+	}
+
+	return rest + j.ThinkTime.duration()
+}
+
+// arrivals runs the job as an open model: calls are made j.Rate times a
+// second, on a fixed schedule, however long each call takes. This avoids
+// coordinated omission, where a slow schedule slows the load test down and
+// hides its own latency.
+//
+// Calls are scheduled from when the job started, rather than from the last
+// call, so that a late call is followed by the next one straight away and the
+// average rate holds. Calls which would take the number in flight over
+// j.MaxInFlight are dropped and counted, rather than queued
+func (j *Job) arrivals() {
+	start := time.Now()
+	done := j.done()
+
+	timer := time.NewTimer(j.arrival(start, 1))
+	defer timer.Stop()
+
+	for n := int64(1); ; n++ {
+		select {
+		case <-done:
+			return
+
+		case <-timer.C:
+			timer.Reset(j.arrival(start, n+1))
+		}
+
+		select {
+		case j.inFlight <- true:
+		default:
+			atomic.AddInt64(&j.dropped, 1)
+
+			continue
+		}
+
+		go func(n int64) {
+			defer func() {
+				<-j.inFlight
+			}()
+
+			j.request(0, n-1)
+		}(n)
+	}
+}
+
+// arrival returns how long until the n'th call of a job with a Rate,
+// which started at start, is due. It's worked out in floating point, as
+// a duration of 1/Rate seconds could lose precision, or overflow
+func (j *Job) arrival(start time.Time, n int64) time.Duration {
+	return time.Until(start.Add(time.Duration(float64(n) / j.Rate * float64(time.Second))))
+}
+
+// request makes a single call to the schedule, for user's iteration'th
+// call, logging any error. It returns false if the call couldn't be made
+// because the job's feeder has run out of records
+func (j *Job) request(user int, iteration int64) bool {
+	atomic.AddInt64(&j.calling, 1)
+	defer atomic.AddInt64(&j.calling, -1)
+
+	err := j.call(user, iteration)
+	if err == errExhausted {
+		return false
+	}
+
+	if err != nil && !j.dropRPCErrors {
+		log.Print(err)
+	}
+
+	return true
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countingRPCClient struct {
+	calls *int64
+	delay time.Duration
+}
+
+func (c countingRPCClient) Call(_ string, _ interface{}, _ interface{}) error {
+	atomic.AddInt64(c.calls, 1)
+	time.Sleep(c.delay)
+
+	return nil
+}
+
+func (c countingRPCClient) Close() error {
+	return nil
+}
+
+func TestJob_Arrivals(t *testing.T) {
+	for _, test := range []struct {
+		name          string
+		rate          float64
+		maxInFlight   int
+		delay         time.Duration
+		expectCalls   int64
+		expectDropped bool
+	}{
+		{"fast schedule", 100, 10, 0, 50, false},
+
+		// A slow schedule doesn't slow the rate down; calls which
+		// can't be made are dropped instead
+		{"slow schedule", 100, 5, 100 * time.Millisecond, 25, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			calls := new(int64)
+
+			j := &Job{
+				Rate:     test.rate,
+				service:  countingRPCClient{calls: calls, delay: test.delay},
+				setup:    true,
+				inFlight: make(chan bool, test.maxInFlight),
+			}
+
+			go j.arrivals()
+			time.Sleep(500 * time.Millisecond)
+			j.setComplete()
+
+			// Allow for a little scheduling slop either way
+			made := atomic.LoadInt64(calls)
+			if made < test.expectCalls-10 || made > test.expectCalls+10 {
+				t.Errorf("expected around %d calls, received %d", test.expectCalls, made)
+			}
+
+			dropped := atomic.LoadInt64(&j.dropped)
+			if test.expectDropped && dropped == 0 {
+				t.Errorf("expected dropped calls")
+			}
+
+			if !test.expectDropped && dropped != 0 {
+				t.Errorf("unexpected %d dropped calls", dropped)
+			}
+		})
+	}
+}
+
+func TestJob_Arrivals_Complete(t *testing.T) {
+	// The first call isn't due for a quarter of an hour, but the job
+	// completing stops arrivals straight away
+	j := &Job{
+		Rate:     MinRate,
+		inFlight: make(chan bool, 1),
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		j.arrivals()
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	j.setComplete()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Errorf("expected arrivals to stop once the job completed")
+	}
+}
+
+func TestJob_Users(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		users       int
+		pacing      int64
+		thinkTime   ThinkTime
+		expectCalls int64
+	}{
+		{"paced", 2, 100, ThinkTime{}, 10},
+		{"paced, with think time", 2, 100, ThinkTime{Distribution: ThinkFixed, Mean: 150}, 4},
+
+		// Calls which take longer than the pacing interval are
+		// followed straight away by the next
+		{"slower than pacing", 1, 10, ThinkTime{}, 10},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			calls := new(int64)
+
+			j := &Job{
+				Pacing:    test.pacing,
+				ThinkTime: test.thinkTime,
+				service:   countingRPCClient{calls: calls, delay: 50 * time.Millisecond},
+				setup:     true,
+				pool:      newUserPool(test.users),
+			}
+
+			go j.users()
+			time.Sleep(490 * time.Millisecond)
+
+			j.setComplete()
+			j.pool.close()
+
+			made := atomic.LoadInt64(calls)
+			if made < test.expectCalls-1 || made > test.expectCalls+1 {
+				t.Errorf("expected around %d calls, received %d", test.expectCalls, made)
+			}
+		})
+	}
+}
+
+func TestJob_Pause(t *testing.T) {
+	j := &Job{
+		Pacing:    100,
+		ThinkTime: ThinkTime{Distribution: ThinkFixed, Mean: 50},
+	}
+
+	for _, test := range []struct {
+		name    string
+		elapsed time.Duration
+		expect  time.Duration
+	}{
+		{"quick call", 30 * time.Millisecond, 120 * time.Millisecond},
+		{"call as long as pacing", 100 * time.Millisecond, 50 * time.Millisecond},
+		{"call overrunning pacing", 300 * time.Millisecond, 50 * time.Millisecond},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			received := j.pause(test.elapsed)
+			if test.expect != received {
+				t.Errorf("expected %s, received %s", test.expect, received)
+			}
+		})
+	}
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// FeedSequential hands out a feeder's records in order, one per
+	// call, whichever user makes it
+	FeedSequential = "sequential"
+
+	// FeedRandom hands out a random record for each call. Random
+	// feeders never run out
+	FeedRandom = "random"
+
+	// FeedUnique gives each user a record of its own, which it uses
+	// for every call it makes
+	FeedUnique = "unique"
+
+	// ExhaustedRecycle starts a feeder which runs out of records
+	// from the beginning again
+	ExhaustedRecycle = "recycle"
+
+	// ExhaustedStopUser stops users which can't be given a record,
+	// without starting new ones in their place
+	ExhaustedStopUser = "stop-user"
+
+	// ExhaustedStopJob ends the job as soon as its feeder runs
+	// out of records
+	ExhaustedStopJob = "stop-job"
+)
+
+var (
+	feederDir  = flag.String("feeders", "/var/lib/go-lo/feeders", "directory of files jobs can read feeder records from, by path relative to it")
+	feederSize = flag.Int64("feeder-size", 64<<20, "largest feeder, in bytes, a job may load")
+
+	// errExhausted is returned for calls which can't be made
+	// because the job's feeder has run out of records
+	errExhausted = fmt.Errorf("feeder has run out of records")
+)
+
+// Feeder is a file of records, such as the credentials to log in with or
+// products to buy, each call to a schedule is sent one of. Path is either
+// a path relative to the agent's feeder directory or an http or https
+// URL. Format is "csv",
+// whose header row names each record's fields, or "jsonl", a json object
+// per line; unset, it's taken from the extension of Path.
+//
+// Strategy is how records are handed out, FeedSequential unless set, and
+// Exhausted what happens when they run out, ExhaustedRecycle unless set
+type Feeder struct {
+	Path      string `json:"path"`
+	Format    string `json:"format"`
+	Strategy  string `json:"strategy"`
+	Exhausted string `json:"exhausted"`
+}
+
+// feeder hands out the records read from a Feeder
+type feeder struct {
+	mutex     sync.RWMutex
+	records   []map[string]string
+	strategy  string
+	exhausted string
+	next      int64
+	once      sync.Once
+	done      chan struct{}
+}
+
+// validate returns an error if f can't be loaded; URLs must be http or
+// https, and paths must exist within the agent's feeder directory
+func (f Feeder) validate() (err error) {
+	u, perr := url.Parse(f.Path)
+	switch {
+	case perr == nil && u.Scheme != "":
+		if u.Scheme != "http" && u.Scheme != "https" {
+			err = fmt.Errorf("feeder needs an http or https url, received %q", f.Path)
+		}
+	case filepath.IsAbs(f.Path) || escapes(f.Path):
+		err = fmt.Errorf("feeder path %q must be relative to the agent's feeder directory", f.Path)
+	default:
+		_, err = os.Stat(filepath.Join(*feederDir, f.Path))
+	}
+
+	return
+}
+
+// loadFeeder reads the records of f, up to -feeder-size bytes of them
+func loadFeeder(f Feeder) (fd *feeder, err error) {
+	err = f.validate()
+	if err != nil {
+		return
+	}
+
+	format := f.Format
+	if format == "" {
+		format = filepath.Ext(f.Path)
+		if len(format) > 0 {
+			format = format[1:]
+		}
+	}
+
+	var r io.ReadCloser
+
+	u, err := url.Parse(f.Path)
+	if err == nil && u.Scheme != "" {
+		r, err = open(f.Path)
+	} else {
+		r, err = os.Open(filepath.Join(*feederDir, f.Path))
+	}
+
+	if err != nil {
+		return
+	}
+
+	defer r.Close()
+
+	fd = &feeder{
+		strategy:  f.Strategy,
+		exhausted: f.Exhausted,
+		done:      make(chan struct{}),
+	}
+
+	// Reading one byte past the limit tells a feeder of exactly
+	// -feeder-size bytes apart from one which is too large
+	lr := &io.LimitedReader{R: r, N: *feederSize + 1}
+
+	switch format {
+	case "csv":
+		fd.records, err = readCSV(lr)
+	case "jsonl":
+		fd.records, err = readJSONL(lr)
+	default:
+		err = fmt.Errorf("unknown feeder format %q", format)
+	}
+
+	if lr.N == 0 {
+		return nil, fmt.Errorf("feeder %s is larger than %d bytes", f.Path, *feederSize)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fd.records) == 0 {
+		return nil, fmt.Errorf("feeder %s has no records", f.Path)
+	}
+
+	return
+}
+
+// feederLoad is a feeder being loaded in the background
+type feederLoad struct {
+	done   chan struct{}
+	feeder *feeder
+	err    error
+}
+
+// preload starts loading f in the background, so that a large or slow
+// feeder doesn't hold up whoever created its job
+func preload(f Feeder) (l *feederLoad) {
+	l = &feederLoad{
+		done: make(chan struct{}),
+	}
+
+	go func() {
+		defer close(l.done)
+
+		l.feeder, l.err = loadFeeder(f)
+	}()
+
+	return
+}
+
+// wait blocks until l has loaded, returning its feeder
+func (l *feederLoad) wait() (*feeder, error) {
+	<-l.done
+
+	return l.feeder, l.err
+}
+
+// waitFeeder returns the job's feeder, once it has loaded, or nil if
+// the job has none or it's loaded already
+func (j *Job) waitFeeder() (*feeder, error) {
+	switch {
+	case j.Feeder.Path == "" || j.feeder != nil:
+		return nil, nil
+	case j.feederLoad != nil:
+		return j.feederLoad.wait()
+	}
+
+	return loadFeeder(j.Feeder)
+}
+
+// readCSV reads records from csv, keyed by the header row
+func readCSV(r io.Reader) (records []map[string]string, err error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil || len(rows) == 0 {
+		return
+	}
+
+	header := rows[0]
+	for _, row := range rows[1:] {
+		record := make(map[string]string)
+		for i, field := range row {
+			record[header[i]] = field
+		}
+
+		records = append(records, record)
+	}
+
+	return
+}
+
+// readJSONL reads records from a json object per line. Values which
+// aren't strings are kept as json
+func readJSONL(r io.Reader) (records []map[string]string, err error) {
+	dec := json.NewDecoder(r)
+
+	for {
+		var raw map[string]json.RawMessage
+
+		err = dec.Decode(&raw)
+		if err == io.EOF {
+			return records, nil
+		}
+
+		if err != nil {
+			return
+		}
+
+		record := make(map[string]string)
+		for k, v := range raw {
+			var s string
+			if json.Unmarshal(v, &s) != nil {
+				s = string(v)
+			}
+
+			record[k] = s
+		}
+
+		records = append(records, record)
+	}
+}
+
+// record returns the record for user's next call, or errExhausted
+// if there isn't one. A nil feeder has no records to give
+func (f *feeder) record(user int) (r map[string]string, err error) {
+	if f == nil {
+		return
+	}
+
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	n := len(f.records)
+	if n == 0 {
+		return nil, errExhausted
+	}
+
+	var i int
+	switch f.strategy {
+	case FeedRandom:
+		return f.records[rand.Intn(n)], nil
+	case FeedUnique:
+		i = user
+	default:
+		i = int(atomic.AddInt64(&f.next, 1) - 1)
+	}
+
+	if i < n {
+		return f.records[i], nil
+	}
+
+	switch f.exhausted {
+	case ExhaustedStopUser:
+	case ExhaustedStopJob:
+		f.once.Do(func() {
+			close(f.done)
+		})
+	default:
+		return f.records[i%n], nil
+	}
+
+	return nil, errExhausted
+}
+
+// close drops a feeder's records, once its job has finished. A closed
+// feeder has no records left to give
+func (f *feeder) close() {
+	if f == nil {
+		return
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.records = nil
+}
+
+// stopped is closed when a feeder which stops its job on running out
+// of records does. It's nil, so never closes, for a nil feeder
+func (f *feeder) stopped() chan struct{} {
+	if f == nil {
+		return nil
+	}
+
+	return f.done
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff"
+)
+
+func TestLoadFeeder(t *testing.T) {
+	dir := "testdata"
+	feederDir = &dir
+
+	size := int64(64 << 20)
+	feederSize = &size
+
+	empty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer empty.Close()
+
+	for _, test := range []struct {
+		name        string
+		feeder      Feeder
+		expect      []map[string]string
+		expectError bool
+	}{
+		{"csv", Feeder{Path: "feeder.csv"}, []map[string]string{
+			{"username": "alice", "password": "hunter2"},
+			{"username": "bob", "password": "correct horse"},
+			{"username": "carol", "password": "swordfish"},
+		}, false},
+		{"jsonl", Feeder{Path: "feeder.jsonl"}, []map[string]string{
+			{"sku": "abc-123", "quantity": "2"},
+			{"sku": "def-456", "quantity": "1", "gift": "true"},
+		}, false},
+		{"explicit format", Feeder{Path: "feeder.jsonl", Format: "csv"}, nil, true},
+		{"unknown format", Feeder{Path: "script/main.go"}, nil, true},
+		{"missing file", Feeder{Path: "nonsuch.csv"}, nil, true},
+		{"no records", Feeder{Path: empty.URL + "/empty.csv"}, nil, true},
+		{"absolute path", Feeder{Path: mustAbs(t, "testdata/feeder.csv")}, nil, true},
+		{"path out of the feeder directory", Feeder{Path: "../testdata/feeder.csv"}, nil, true},
+		{"file url", Feeder{Path: "file://" + mustAbs(t, "testdata/feeder.jsonl")}, nil, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := loadFeeder(test.feeder)
+			if test.expectError && err == nil {
+				t.Errorf("expected error")
+			}
+
+			if !test.expectError && err != nil {
+				t.Errorf("unexpected error %+v", err)
+			}
+
+			if test.expectError {
+				return
+			}
+
+			if !reflect.DeepEqual(test.expect, f.records) {
+				t.Errorf("expected %v, received %v", test.expect, f.records)
+			}
+		})
+	}
+}
+
+func TestLoadFeeder_Size(t *testing.T) {
+	dir := "testdata"
+	feederDir = &dir
+
+	defer func(size int64) {
+		feederSize = &size
+	}(*feederSize)
+
+	fi, err := os.Stat("testdata/feeder.csv")
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	for _, test := range []struct {
+		name        string
+		size        int64
+		expectError bool
+	}{
+		{"within the limit", fi.Size(), false},
+		{"past the limit", fi.Size() - 1, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			feederSize = &test.size
+
+			_, err := loadFeeder(Feeder{Path: "feeder.csv"})
+			if test.expectError && err == nil {
+				t.Errorf("expected error")
+			}
+
+			if !test.expectError && err != nil {
+				t.Errorf("unexpected error %+v", err)
+			}
+		})
+	}
+}
+
+func TestPreload(t *testing.T) {
+	dir := "testdata"
+	feederDir = &dir
+
+	j := Job{
+		Feeder:     Feeder{Path: "feeder.csv"},
+		feederLoad: preload(Feeder{Path: "feeder.csv"}),
+	}
+
+	fd, err := j.waitFeeder()
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	if len(fd.records) != 3 {
+		t.Errorf("expected 3 records, received %d", len(fd.records))
+	}
+}
+
+func mustAbs(t *testing.T, path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	return abs
+}
+
+func TestFeeder_Record(t *testing.T) {
+	records := []map[string]string{{"id": "0"}, {"id": "1"}, {"id": "2"}}
+
+	for _, test := range []struct {
+		name      string
+		strategy  string
+		exhausted string
+		users     []int
+		expect    []string
+		stopped   bool
+	}{
+		{"sequential, recycling", FeedSequential, ExhaustedRecycle, []int{0, 1, 0, 1, 0}, []string{"0", "1", "2", "0", "1"}, false},
+		{"sequential, stopping users", FeedSequential, ExhaustedStopUser, []int{0, 1, 0, 1}, []string{"0", "1", "2", ""}, false},
+		{"sequential, stopping the job", FeedSequential, ExhaustedStopJob, []int{0, 1, 0, 1}, []string{"0", "1", "2", ""}, true},
+		{"unique, recycling", FeedUnique, ExhaustedRecycle, []int{0, 0, 4}, []string{"0", "0", "1"}, false},
+		{"unique, stopping users", FeedUnique, ExhaustedStopUser, []int{2, 2, 3}, []string{"2", "2", ""}, false},
+		{"defaults", "", "", []int{0, 0, 0, 0}, []string{"0", "1", "2", "0"}, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			f := &feeder{
+				records:   records,
+				strategy:  test.strategy,
+				exhausted: test.exhausted,
+				done:      make(chan struct{}),
+			}
+
+			for i, user := range test.users {
+				r, err := f.record(user)
+				if test.expect[i] == "" {
+					if err != errExhausted {
+						t.Errorf("call %d: expected %v, received %v", i, errExhausted, err)
+					}
+
+					continue
+				}
+
+				if err != nil {
+					t.Errorf("call %d: unexpected error %+v", i, err)
+				}
+
+				if test.expect[i] != r["id"] {
+					t.Errorf("call %d: expected %q, received %q", i, test.expect[i], r["id"])
+				}
+			}
+
+			select {
+			case <-f.stopped():
+				if !test.stopped {
+					t.Errorf("unexpected stop")
+				}
+			default:
+				if test.stopped {
+					t.Errorf("expected feeder to stop the job")
+				}
+			}
+		})
+	}
+
+	t.Run("random", func(t *testing.T) {
+		f := &feeder{records: records, strategy: FeedRandom}
+
+		for i := 0; i < 100; i++ {
+			_, err := f.record(0)
+			if err != nil {
+				t.Fatalf("unexpected error %+v", err)
+			}
+		}
+	})
+
+	t.Run("no feeder", func(t *testing.T) {
+		var f *feeder
+
+		r, err := f.record(0)
+		if r != nil || err != nil {
+			t.Errorf("expected no record and no error, received %v and %v", r, err)
+		}
+
+		if f.stopped() != nil {
+			t.Errorf("expected a nil feeder never to stop")
+		}
+	})
+}
+
+func TestFakeRuntime_Feeder(t *testing.T) {
+	expoBackoff = backoff.NewExponentialBackOff()
+	expoBackoff.MaxElapsedTime = time.Second
+
+	logDir = &td
+	RPCCommand = "Server.Run"
+
+	dir := "testdata"
+	feederDir = &dir
+
+	j := Job{
+		Name:     "fake",
+		Duration: 30,
+		Pacing:   10,
+		Users:    2,
+		Feeder:   Feeder{Path: "feeder.csv", Exhausted: ExhaustedStopJob},
+		runtime:  new(fakeRuntime),
+	}
+
+	start := time.Now()
+
+	err := j.Start(discardSink{})
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	if time.Since(start) > 10*time.Second {
+		t.Errorf("expected job to stop once its feeder ran out, took %s", time.Since(start))
+	}
+}
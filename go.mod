@@ -3,7 +3,12 @@ module github.com/go-lo/agent
 go 1.13
 
 require (
-	github.com/abiosoft/semaphore v0.0.0-20180811165425-cb737ff681bd
 	github.com/cenkalti/backoff v2.2.1+incompatible
+	github.com/go-lo/agent/agent v0.0.0-20200226082346-0fc95ee06442
 	github.com/go-lo/go-lo v0.0.0-20200226064935-0c6ade23bbcc
+	github.com/gofrs/uuid v3.2.0+incompatible
+	github.com/golang/protobuf v1.3.3
+	google.golang.org/grpc v1.27.1
 )
+
+replace github.com/go-lo/agent/agent => ./agent
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/go-lo/agent/agent"
+	"google.golang.org/grpc"
+)
+
+const (
+	// TransportRPC drives schedules over net/rpc, as schedules
+	// built with github.com/go-lo/go-lo serve
+	TransportRPC = "rpc"
+
+	// TransportGRPC drives schedules which serve agent.Schedule
+	// over gRPC
+	TransportGRPC = "grpc"
+
+	// readyTimeout is how long a gRPC schedule has to say whether
+	// it's ready before being asked again
+	readyTimeout = time.Second
+)
+
+// grpcClient calls a schedule serving the agent.Schedule service.
+// It implements rpcClient
+type grpcClient struct {
+	conn   *grpc.ClientConn
+	client agent.ScheduleClient
+}
+
+// dialGRPC returns a grpcClient whose connection is made with dial
+func dialGRPC(dial func() (net.Conn, error)) (c grpcClient, err error) {
+	c.conn, err = grpc.Dial("schedule",
+		grpc.WithInsecure(),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return dial()
+		}),
+	)
+
+	if err != nil {
+		return
+	}
+
+	c.client = agent.NewScheduleClient(c.conn)
+
+	return
+}
+
+// ready returns an error unless the schedule is ready to be run
+func (c grpcClient) ready() (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), readyTimeout)
+	defer cancel()
+
+	r, err := c.client.Ready(ctx, &agent.ReadyRequest{}, grpc.WaitForReady(true))
+	if err == nil && !r.Ready {
+		err = fmt.Errorf("schedule is not ready")
+	}
+
+	return
+}
+
+// Call runs the schedule once. gRPC schedules only serve Run, so method
+// is ignored, as is reply. args is sent when it's a RunRequest
+func (c grpcClient) Call(_ string, args interface{}, _ interface{}) (err error) {
+	req, ok := args.(*agent.RunRequest)
+	if !ok {
+		req = &agent.RunRequest{}
+	}
+
+	_, err = c.client.Run(context.Background(), req)
+
+	return
+}
+
+func (c grpcClient) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-lo/agent/agent"
+	"google.golang.org/grpc"
+)
+
+type dummyScheduleServer struct {
+	ready bool
+	runs  *int64
+}
+
+func (s dummyScheduleServer) Ready(context.Context, *agent.ReadyRequest) (*agent.ReadyResponse, error) {
+	return &agent.ReadyResponse{Ready: s.ready}, nil
+}
+
+func (s dummyScheduleServer) Run(context.Context, *agent.RunRequest) (*agent.RunResponse, error) {
+	atomic.AddInt64(s.runs, 1)
+
+	return &agent.RunResponse{}, nil
+}
+
+func TestGRPCClient(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		ready       bool
+		expectError bool
+	}{
+		{"ready", true, false},
+		{"not ready", false, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("unexpected error %+v", err)
+			}
+
+			runs := new(int64)
+
+			s := grpc.NewServer()
+			agent.RegisterScheduleServer(s, dummyScheduleServer{ready: test.ready, runs: runs})
+
+			go s.Serve(l)
+			defer s.Stop()
+
+			c, err := dialGRPC(func() (net.Conn, error) {
+				return net.Dial("tcp", l.Addr().String())
+			})
+
+			if err != nil {
+				t.Fatalf("unexpected error %+v", err)
+			}
+
+			defer c.Close()
+
+			err = c.ready()
+			if test.expectError && err == nil {
+				t.Errorf("expected error")
+			}
+
+			if !test.expectError && err != nil {
+				t.Errorf("unexpected error %+v", err)
+			}
+
+			for i := 0; i < 5; i++ {
+				err = c.Call(RPCCommand, nil, nil)
+				if err != nil {
+					t.Errorf("unexpected error %+v", err)
+				}
+			}
+
+			if *runs != 5 {
+				t.Errorf("expected %d runs, received %d", 5, *runs)
+			}
+		})
+	}
+}
+
+func TestGRPCClient_NothingListening(t *testing.T) {
+	c, err := dialGRPC(func() (net.Conn, error) {
+		return net.Dial("tcp", "127.0.0.1:1")
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	defer c.Close()
+
+	if c.ready() == nil {
+		t.Errorf("expected error")
+	}
+}
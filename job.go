@@ -3,19 +3,19 @@ package main
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net"
 	"net/rpc"
 	"os"
-	"os/exec"
-	"path/filepath"
-	"syscall"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/abiosoft/semaphore"
 	"github.com/cenkalti/backoff"
+	"github.com/go-lo/agent/agent"
 	"github.com/go-lo/go-lo"
 )
 
@@ -23,12 +23,44 @@ const (
 	// DefaultUserCount is the default number of users to run loadtests
 	// to simulate when not specified/ missing
 	DefaultUserCount = 25
+
+	// DefaultPacing is the default time, in milliseconds, between the
+	// start of one call by a user and the start of its next
+	DefaultPacing = 1000
+
+	// DefaultGracePeriod is the default time, in seconds, a job waits
+	// for calls in flight to finish before stopping its schedule
+	DefaultGracePeriod = 10
+
+	// DefaultStopTimeout is the default time, in seconds, a job waits
+	// for its schedule to exit after SIGTERM before killing it
+	DefaultStopTimeout = 10
+
+	// DefaultMaxInFlight is the default number of calls a job with a
+	// Rate can be waiting on at once
+	DefaultMaxInFlight = 1000
+
+	// DefaultConnections is the default number of connections a job
+	// makes to its schedule
+	DefaultConnections = 1
+
+	// MinRate and MaxRate bound a job's Rate, in calls a second: from
+	// one call every quarter of an hour to as many as a single agent
+	// could hope to make
+	MinRate = 1.0 / 900
+	MaxRate = 100000.0
+
+	// flushTimeout is how long to wait, once a schedule has been killed,
+	// for the rest of its output to be read
+	flushTimeout = 5 * time.Second
 )
 
 var (
 	// RPCCommand is the command to request from our RPC'd up scheduler
 	RPCCommand = "Server.Run"
 
+	// expoBackoff is copied by each job as it connects to its schedule,
+	// so that jobs running side by side don't share, and reset, state
 	expoBackoff = backoff.NewExponentialBackOff()
 )
 
@@ -42,43 +74,168 @@ type rpcClient interface {
 // this job, including process information, service clients, stdout/err
 // and flags to determine whether the job is complete
 type Job struct {
+	ID       string `json:"id"`
 	Name     string `json:"name"`
 	Users    int    `json:"users"`
 	Duration int64  `json:"duration"`
 	Binary   string `json:"binary"`
 
+	// Runtime is how the schedule is run; either from Container, or from
+	// Binary, or, for testing, in-process. See newRuntime
+	Runtime   string `json:"runtime"`
+	Container string `json:"container"`
+
+	// SHA256 is the digest a Binary fetched from a URL must have, and
+	// Signature its base64 encoded ed25519 signature, which agents with
+	// trusted keys require
+	SHA256    string `json:"sha256"`
+	Signature string `json:"signature"`
+
+	// Args, Env and Workdir are passed to the schedule. Env values of
+	// the form secret:<name> are read from the agent's secrets directory
+	// as the schedule starts
+	Args    []string          `json:"args"`
+	Env     map[string]string `json:"env"`
+	Workdir string            `json:"workdir"`
+
+	// Shared jobs can run alongside other shared jobs, where the agent
+	// has the capacity. Jobs are exclusive, running alone, by default
+	Shared bool `json:"shared"`
+
+	// Connections is the number of connections calls to the schedule
+	// are spread across, and Balance how they're spread; either
+	// BalanceRoundRobin, the default, or BalanceLeastOutstanding
+	Connections int    `json:"connections"`
+	Balance     string `json:"balance"`
+
+	// Transport is how the schedule is called; either TransportRPC,
+	// the default, or TransportGRPC
+	Transport string `json:"transport"`
+
+	// Scenarios, when set, are the user journeys the schedule can run,
+	// mixed by weight. See Scenario
+	Scenarios []Scenario `json:"scenarios"`
+
+	// Params are sent to the schedule with every call. See Call
+	Params map[string]string `json:"params"`
+
+	// Feeder, when set, sends each call a record from a file. See Feeder
+	Feeder Feeder `json:"feeder"`
+
+	// Thresholds are pass/fail conditions on the job's results. See
+	// Threshold
+	Thresholds []Threshold `json:"thresholds"`
+
+	// Sinks are where the job's results are sent; by default, just
+	// the agent's collector
+	Sinks []SinkConfig `json:"sinks"`
+
+	// Legacy jobs' schedules serve RPC on go-lo's fixed address, rather
+	// than the one the agent gives them, as schedules built against older
+	// versions of go-lo do. Only one runs at a time
+	Legacy bool `json:"legacy"`
+
+	// Stages, when set, replace Users and Duration with a load profile
+	// where the number of users changes over the course of the job
+	Stages []Stage `json:"stages"`
+
+	// Pacing is the time, in milliseconds, between the start of one call
+	// by a user and the start of its next; a user whose call takes longer
+	// than this calls again straight away. ThinkTime adds a further, random,
+	// wait between calls
+	Pacing    int64     `json:"pacing"`
+	ThinkTime ThinkTime `json:"thinkTime"`
+
+	// Rate, when set, runs the job as an open model: rather than users
+	// making calls as fast as the schedule responds, calls are made Rate
+	// times a second regardless of how long each takes. MaxInFlight caps
+	// the number of calls waiting on a response; calls which would go over
+	// the cap are dropped, and counted
+	Rate        float64 `json:"rate"`
+	MaxInFlight int     `json:"maxInFlight"`
+
+	// GracePeriod is how long, in seconds, a finished job waits for calls
+	// already made to finish. The schedule is then sent SIGTERM, and given
+	// StopTimeout seconds to exit before being killed
+	GracePeriod int64 `json:"gracePeriod"`
+	StopTimeout int64 `json:"stopTimeout"`
+
+	// Limits, when set, run the schedule in a cgroup which caps the
+	// resources it can use
+	Limits Limits `json:"limits"`
+
 	bin           binary
-	items         int
-	process       *os.Process
-	connection    net.Conn
+	feeder        *feeder
+	feederLoad    *feederLoad
+	items         int64
+	runtime       runtime
+	rpc           rpcAddr
+	exit          exit
+	exited        chan struct{}
+	phase         int32
+	exitPhase     agent.Phase
+	calling       int64
+	oomKilled     bool
+	client        rpcClient
 	setup         bool
 	complete      bool
+	completed     chan struct{}
+	cancelled     bool
 	success       bool
 	dropRPCErrors bool
+	stop          chan struct{}
 	service       rpcClient
-	outputChan    chan golo.Output
-	sem           *semaphore.Semaphore
+	conns         *connPool
+	sinks         *fanout
+	watchers      *broadcaster
+	summary       *summary
+	final         *agent.Summary
+	thresholds    []*threshold
+	pool          *userPool
+	inFlight      chan bool
+	dropped       int64
 	stdout        *bufio.Reader
 	stderr        *bufio.Reader
 	logfile       io.Writer
 	errfile       io.Writer
+
+	// mutex guards the state the job's own goroutines change while
+	// status reads it: its flags, how its schedule exited, and what it
+	// sets up as it starts
+	mutex sync.Mutex
+
+	state    agent.JobStatus_State
+	err      error
+	queued   time.Time
+	started  time.Time
+	finished time.Time
 }
 
-// Start will, given a valid output chan hooked up to a collector client,
-// start a loadtest schedule binary, slurp it's stdout/err and then stop it
+// Start will, given the agent's collector, start a loadtest schedule
+// binary, slurp it's stdout/err into the job's sinks and then stop it
 // once j.Duration seconds pass
-func (j *Job) Start(outputChan chan golo.Output) (err error) {
-	err = j.initialiseJob(outputChan)
+func (j *Job) Start(collector Sink) (err error) {
+	if j.isCancelled() {
+		return
+	}
+
+	err = j.initialiseJob(collector)
 	if err != nil {
 		return
 	}
 
+	// Deferred first, so run last, once tail has finished
+	defer j.sinks.close(flushTimeout)
+
 	err = j.execute()
 	if err != nil {
 		return
 	}
 
+	tailed := make(chan struct{})
 	go func() {
+		defer close(tailed)
+
 		err := j.tail()
 		if err != nil && err != io.EOF {
 			log.Print(err)
@@ -86,19 +243,26 @@ func (j *Job) Start(outputChan chan golo.Output) (err error) {
 	}()
 
 	defer func() {
-		if j.process != nil {
-			j.process.Kill()
-
-			state, _ := j.process.Wait()
-			if !state.Success() {
-				status := state.Sys().(syscall.WaitStatus)
-
-				log.Printf("%s exited with status %d, stop signal %s",
-					j.Binary,
-					status.ExitStatus(),
-					status.Signal(),
-				)
-			}
+		j.setComplete()
+		j.pool.close()
+
+		if j.exited != nil {
+			j.stopProcess()
+		}
+
+		j.rpc.remove()
+
+		// Give tail a chance to read whatever the schedule wrote
+		// before it was killed
+		select {
+		case <-tailed:
+		case <-time.After(flushTimeout):
+		}
+
+		// With every result in, a job which would otherwise have
+		// succeeded fails if it breached any of its thresholds
+		if err == nil && !j.isCancelled() {
+			err = j.breached(false, 0)
 		}
 	}()
 
@@ -107,83 +271,232 @@ func (j *Job) Start(outputChan chan golo.Output) (err error) {
 		return
 	}
 
-	defer j.connection.Close()
 	defer j.service.Close()
 
+	j.mutex.Lock()
 	j.setup = true
+	j.mutex.Unlock()
 
-	go func() {
-		for {
-			if j.complete {
-				return
+	if j.Rate > 0 {
+		go j.arrivals()
+	} else {
+		go j.users()
+	}
+
+	start := time.Now()
+
+	// Once a second test whether we've gone past the expected duration of a test,
+	// and break out if we have. Each tick also resizes the user pool to follow the
+	// job's stages, if it has any.
+	//
+	// If the schedule exits in the meantime, the job is cancelled, its feeder
+	// runs out of records and should stop it, or it breaches a threshold which
+	// aborts it, break out straight away.
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+loop:
+	for {
+		select {
+		case <-j.stop:
+			break loop
+
+		case <-j.exited:
+			err = fmt.Errorf("%s exited early: %s", j.Name, j.exit)
+
+			break loop
+
+		case <-j.feeder.stopped():
+			log.Printf("%s: %s", j.Name, errExhausted)
+
+			break loop
+
+		case <-ticker.C:
+			err = j.breached(true, time.Since(start))
+			if err != nil {
+				break loop
 			}
 
-			j.sem.Acquire()
-			go func() {
-				defer j.sem.Release()
+			if time.Since(start).Seconds() >= float64(j.Duration) {
+				break loop
+			}
 
-				err = j.TryRequest()
-				if err != nil && !j.dropRPCErrors {
-					log.Print(err)
-				}
-			}()
+			j.pool.resize(j.usersAt(time.Since(start)))
 		}
-	}()
+	}
 
-	start := time.Now()
+	// Stop making new calls, and give those already made a chance to
+	// finish before the schedule is stopped
+	j.setComplete()
+	j.pool.close()
+	j.drain()
 
-	// Once a second test whether we've gone past the expected duration of a test.
-	// If we have, break out. If not then try and determine whether the schedule is
-	// still running by signalling to it. If it no longer exists, break out also.
-	//
-	// If the PID is reassigned to another long running process in the time between
-	// crash and tick then this supervisor will, erroneously, believe the scheule is
-	// still running. The probability of this is, happily, low enough that this solution
-	// is Good Enough tm
-	ticker := time.Tick(1 * time.Second)
-	for _ = range ticker {
-		if time.Since(start).Seconds() >= float64(j.Duration) {
-			break
-		}
+	return
+}
 
-		err = j.process.Signal(syscall.SIGUSR1)
-		if err != nil {
-			break
-		}
+// Cancel stops a job. A running job stops issuing requests, kills its
+// schedule and flushes the remaining output, as it would on completion.
+// A job which hasn't started yet never will
+func (j *Job) Cancel() {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	if j.cancelled {
+		return
+	}
+
+	j.cancelled = true
+	if j.stop != nil {
+		close(j.stop)
+	}
+}
+
+// isCancelled returns whether the job has been cancelled
+func (j *Job) isCancelled() bool {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	return j.cancelled
+}
+
+// isComplete returns whether the job has stopped making calls
+func (j *Job) isComplete() bool {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	return j.complete
+}
+
+// setComplete stops the job making calls
+func (j *Job) setComplete() {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	if j.completed != nil && !j.complete {
+		close(j.completed)
 	}
 
 	j.complete = true
+}
 
-	return
+// done returns a channel which is closed once the job stops making
+// calls, for goroutines which wait on something else in the meantime
+func (j *Job) done() <-chan struct{} {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	if j.completed == nil {
+		j.completed = make(chan struct{})
+		if j.complete {
+			close(j.completed)
+		}
+	}
+
+	return j.completed
 }
 
-func (j *Job) initialiseJob(outputChan chan golo.Output) (err error) {
+// initialiseJob fills in the job's defaults, and sets up what it needs
+// to run. It holds the job's lock throughout, as status reads the
+// fields it sets
+func (j *Job) initialiseJob(collector Sink) (err error) {
+	// A feeder still loading is waited for before taking the lock,
+	// so the job's status can be read in the meantime
+	fd, err := j.waitFeeder()
+	if err != nil {
+		return
+	}
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
 	err = j.openLogFile()
 	if err != nil {
 		return
 	}
 
-	j.outputChan = outputChan
+	if j.summary == nil {
+		j.summary = newSummary()
+	}
+
+	if len(j.Stages) > 0 {
+		j.Duration = j.stagesDuration()
+		j.Users = j.peakUsers()
+	}
 
 	if j.Users == 0 {
 		j.Users = DefaultUserCount
 	}
-	j.sem = semaphore.New(j.Users)
+	j.pool = newUserPool(j.usersAt(0))
+
+	if j.Pacing == 0 {
+		j.Pacing = DefaultPacing
+	}
+
+	if j.MaxInFlight == 0 {
+		j.MaxInFlight = DefaultMaxInFlight
+	}
+	j.inFlight = make(chan bool, j.MaxInFlight)
+
+	if j.GracePeriod == 0 {
+		j.GracePeriod = DefaultGracePeriod
+	}
+
+	if j.StopTimeout == 0 {
+		j.StopTimeout = DefaultStopTimeout
+	}
+
+	if j.Connections == 0 {
+		j.Connections = DefaultConnections
+	}
+
+	if fd != nil {
+		j.feeder = fd
+	}
+
+	if len(j.Thresholds) > 0 && j.thresholds == nil {
+		j.thresholds, err = parseThresholds(j.Thresholds)
+		if err != nil {
+			return
+		}
+	}
+
+	j.sinks, err = j.newSinks(collector)
 
 	return
 }
 
 func (j *Job) initialiseRPC() (err error) {
-	err = backoff.Retry(j.TryConnect, expoBackoff)
+	b := *expoBackoff
+	b.Reset()
+
+	err = backoff.Retry(j.TryConnect, &b)
 	if err != nil {
 		return
 	}
-	expoBackoff.Reset()
+	b.Reset()
+
+	// Once the schedule is listening, the rest of the job's
+	// connections shouldn't need retrying
+	clients := []rpcClient{j.client}
+	for len(clients) < j.Connections {
+		var c rpcClient
+
+		c, err = j.newClient()
+		if err != nil {
+			newConnPool(clients, j.Balance).Close()
+
+			return
+		}
+
+		clients = append(clients, c)
+	}
 
-	j.service = rpc.NewClient(j.connection)
+	j.mutex.Lock()
+	j.conns = newConnPool(clients, j.Balance)
+	j.service = j.conns
+	j.mutex.Unlock()
 
-	err = backoff.Retry(j.TryRequest, expoBackoff)
-	expoBackoff.Reset()
+	err = backoff.Retry(j.TryRequest, &b)
 
 	return
 }
@@ -192,24 +505,88 @@ func (j *Job) initialiseRPC() (err error) {
 // up and listening on the correct address
 func (j *Job) TryConnect() (err error) {
 	log.Print("try connect")
-	j.connection, err = net.Dial("tcp", golo.RPCAddr)
+	j.client, err = j.newClient()
 
 	return
 }
 
+// newClient connects a new client to the schedule, over the job's
+// transport. gRPC schedules are asked whether they're ready, too
+func (j *Job) newClient() (c rpcClient, err error) {
+	if j.Transport != TransportGRPC {
+		var conn net.Conn
+
+		conn, err = j.dial()
+		if err != nil {
+			return
+		}
+
+		return rpc.NewClient(conn), nil
+	}
+
+	g, err := dialGRPC(j.dial)
+	if err != nil {
+		return
+	}
+
+	err = g.ready()
+	if err != nil {
+		g.Close()
+
+		return nil, err
+	}
+
+	return g, nil
+}
+
+// dial connects to the address the schedule serves RPC on
+func (j *Job) dial() (net.Conn, error) {
+	addr := legacyRPCAddr
+	if j.runtime != nil {
+		addr = j.runtime.address()
+	}
+
+	return net.Dial(addr.network, addr.address)
+}
+
 // TryRequest will run an RPC call to the golo RPC server
 // listening on https://godoc.org/github.com/go-lo/go-lo#Server.Run
+// (or the method of one of the job's scenarios)
 // it's used to test whether a schedule is ready to be run (via
 // exponential backoff) and then, once ready, to actuall perform
 // tests
 func (j *Job) TryRequest() (err error) {
-	if !j.setup {
+	return j.send(j.newProbe())
+}
+
+// call makes a call to the schedule on behalf of user, on its
+// iteration'th call, telling the schedule both
+func (j *Job) call(user int, iteration int64) (err error) {
+	c, err := j.newCall(user, iteration)
+	if err != nil {
+		return
+	}
+
+	return j.send(c)
+}
+
+// send makes the call c to the schedule
+func (j *Job) send(c Call) (err error) {
+	j.mutex.Lock()
+	setup := j.setup
+	j.mutex.Unlock()
+
+	if !setup {
 		log.Print("try request")
 	}
 
-	err = j.service.Call(RPCCommand, &golo.NullArg{}, &golo.NullArg{})
+	if j.Transport == TransportGRPC {
+		err = j.service.Call(c.method(), c.proto(), nil)
+	} else {
+		err = j.service.Call(c.method(), &c, &golo.NullArg{})
+	}
 
-	if err != nil && !j.complete {
+	if err != nil && !j.isComplete() {
 		return
 	}
 
@@ -217,117 +594,137 @@ func (j *Job) TryRequest() (err error) {
 }
 
 func (j *Job) execute() (err error) {
-	cmd := exec.Command(j.bin.Path)
-
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		return
+	if j.runtime == nil {
+		j.runtime = new(localRuntime)
 	}
 
-	j.stderr = bufio.NewReader(stderr)
+	if j.Legacy {
+		j.rpc = legacyRPCAddr
+	}
 
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return
+	if j.rpc.address == "" {
+		j.rpc, err = newRPCAddr(j.ID)
+		if err != nil {
+			return
+		}
 	}
 
-	j.stdout = bufio.NewReader(stdout)
+	j.rpc.release()
 
-	err = cmd.Start()
+	err = j.runtime.start(j)
 	if err != nil {
+		j.rpc.remove()
+
 		return
 	}
 
-	j.process = cmd.Process
+	j.stdout = bufio.NewReader(j.runtime.stdout())
+	j.stderr = bufio.NewReader(j.runtime.stderr())
+	j.exited = make(chan struct{})
+
+	j.setPhase(agent.Phase_RUNNING)
+	go j.wait()
 
 	return
 }
 
+// tail reads the schedule's stdout until it closes, turning each line
+// into an Output for the job's sinks, and logs its stderr alongside. It
+// returns once both have closed
 func (j *Job) tail() (err error) {
-	defer func() {
-		j.complete = true
-	}()
+	defer j.setComplete()
 
 	// log stderr straight out
+	stderred := make(chan struct{})
 	go func() {
-		for {
-			if j.stderr == nil {
-				continue
-			}
+		defer close(stderred)
 
-			if j.complete {
-				return
-			}
+		if j.stderr == nil {
+			return
+		}
 
+		// A line too long to scan is skipped, rather than leaving the
+		// schedule blocked on writing the rest of it
+		for {
 			scanner := bufio.NewScanner(j.stderr)
 			for scanner.Scan() {
 				j.logerr(scanner.Bytes())
 			}
 
-			if err := scanner.Err(); err != nil {
-				continue
+			if scanner.Err() != bufio.ErrTooLong {
+				return
 			}
 		}
 	}()
 
-	for {
-		if j.complete {
+	defer func() {
+		<-stderred
+	}()
+
+	scanner := bufio.NewScanner(j.stdout)
+	for scanner.Scan() {
+		// The scanner reuses its buffer for the next line
+		line := append([]byte(nil), scanner.Bytes()...)
+		j.logline(line)
+
+		// For now we unmarshal output back into a golo.Output
+		// as a way of ensuring the content read from the binary is
+		// valid to be sent to the collector endpoint. This is to ensure
+		// that the collector has largely decent data to work with, and
+		// that if there any errors we can get that data from an agent
+		// running the test, rather than picking it out of the collector
+		// logs and trying to traceback to where the data came from.
+		//
+		// The downside to all of this is the latency and complexity
+		// of all of this unmarshalling/ marshalling back and forth.
+		// It's also a bit of a false assumption- if the body of the
+		// line from the scheduler is a valid json object then we're
+		// still going to have a golo.Output- it just either wont
+		// contain anything, or what it does contain will be garbage.
+		o := new(Output)
+
+		err = json.Unmarshal(line, o)
+		if err != nil {
+			log.Print(string(line))
+			log.Print(err)
+
 			return
 		}
 
-		var line []byte
-
-		scanner := bufio.NewScanner(j.stdout)
-		for scanner.Scan() {
-			line = scanner.Bytes()
-			go j.logline(line)
-
-			// For now we unmarshal output back into a golo.Output
-			// as a way of ensuring the content read from the binary is
-			// valid to be sent to the collector endpoint. This is to ensure
-			// that the collector has largely decent data to work with, and
-			// that if there any errors we can get that data from an agent
-			// running the test, rather than picking it out of the collector
-			// logs and trying to traceback to where the data came from.
-			//
-			// The downside to all of this is the latency and complexity
-			// of all of this unmarshalling/ marshalling back and forth.
-			// It's also a bit of a false assumption- if the body of the
-			// line from the scheduler is a valid json object then we're
-			// still going to have a golo.Output- it just either wont
-			// contain anything, or what it does contain will be garbage.
-			o := new(golo.Output)
-
-			err = json.Unmarshal(line, o)
-			if err != nil {
-				log.Print(string(line))
-				log.Print(err)
-
-				return
-			}
+		j.tag(o)
 
-			j.items++
-			j.outputChan <- *o
+		atomic.AddInt64(&j.items, 1)
+		j.summary.add(*o)
+		for _, t := range j.thresholds {
+			t.add(*o)
 		}
 
-		if err := scanner.Err(); err != nil {
-			continue
+		if j.watchers != nil {
+			j.watchers.publish(*o)
 		}
 
+		j.sinks.publish(*o)
+	}
+
+	// Runtimes close the schedule's pipes once it has exited, which
+	// is as much the end of its output as EOF
+	err = scanner.Err()
+	if errors.Is(err, os.ErrClosed) {
+		err = nil
 	}
+
+	return
 }
 
 func (j *Job) openLogFile() (err error) {
-	os.MkdirAll(filepath.Join(*logDir, j.Name), os.ModePerm)
-	logPath := filepath.Join(*logDir, j.Name, "out.log")
-	errPath := filepath.Join(*logDir, j.Name, "err.log")
+	os.MkdirAll(j.logDir(), os.ModePerm)
 
-	j.logfile, err = os.OpenFile(logPath, os.O_RDWR|os.O_CREATE, 0644)
+	j.logfile, err = os.OpenFile(j.logPath(agent.LogsRequest_STDOUT), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
 	if err != nil {
 		return
 	}
 
-	j.errfile, err = os.OpenFile(errPath, os.O_RDWR|os.O_CREATE, 0644)
+	j.errfile, err = os.OpenFile(j.logPath(agent.LogsRequest_STDERR), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
 	if err != nil {
 		return
 	}
@@ -335,14 +732,14 @@ func (j *Job) openLogFile() (err error) {
 	return
 }
 
-func (j Job) logerr(line []byte) {
+func (j *Job) logerr(line []byte) {
 	j.log(j.errfile, line)
 }
 
-func (j Job) logline(line []byte) {
+func (j *Job) logline(line []byte) {
 	j.log(j.logfile, line)
 }
 
-func (j Job) log(f io.Writer, line []byte) {
+func (j *Job) log(f io.Writer, line []byte) {
 	fmt.Fprintf(f, "%s\n", string(line))
 }
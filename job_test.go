@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -35,17 +36,21 @@ func (c dummyRPCClient) Close() error {
 }
 
 var (
-	dummyServerCalls int
+	dummyServerCalls int64
 )
 
 type DummyServer struct {
 	err bool
+
+	// calls counts calls to this server alone, so that calls still
+	// in flight from earlier tests can't make it error early
+	calls *int64
 }
 
 func (s DummyServer) Run(_ *golo.NullArg, _ *golo.NullArg) error {
-	dummyServerCalls++
+	atomic.AddInt64(&dummyServerCalls, 1)
 
-	if s.err && dummyServerCalls > 1 {
+	if s.err && atomic.AddInt64(s.calls, 1) > 1 {
 		return fmt.Errorf("an error")
 	}
 	return nil
@@ -98,6 +103,31 @@ func TestJob_TryRequest(t *testing.T) {
 	}
 }
 
+func TestJob_TryRequest_Feeder(t *testing.T) {
+	records := []map[string]string{{"id": "0"}, {"id": "1"}}
+
+	j := &Job{
+		service: dummyRPCClient{},
+		setup:   true,
+		feeder:  &feeder{records: records, strategy: FeedSequential},
+	}
+
+	err := j.TryRequest()
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	// Probing the schedule doesn't use up a record
+	c, err := j.newCall(0, 0)
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	if c.Record["id"] != "0" {
+		t.Errorf("expected %q, received %q", "0", c.Record["id"])
+	}
+}
+
 func TestOpenLogFile(t *testing.T) {
 	for _, test := range []struct {
 		name        string
@@ -176,7 +206,7 @@ func TestJob_Initialise(t *testing.T) {
 				j.Users = *test.users
 			}
 
-			err := j.initialiseJob(make(chan golo.Output))
+			err := j.initialiseJob(discardSink{})
 			if test.expectError && err == nil {
 				t.Errorf("expected error")
 			}
@@ -250,17 +280,18 @@ func TestJob_Start(t *testing.T) {
 		name        string
 		runner      interface{}
 		logDir      string
-		job         Job
+		job         *Job
 		doRPC       bool
 		expectError bool
 	}{
-		{"happy path", DummyServer{}, td, Job{Name: "test", Duration: 1, bin: binary{Path: "testdata/dummy-process"}}, true, false},
-		{"dodgy log dir", DummyServer{}, "/", Job{Name: "test", Duration: 1, bin: binary{Path: "testdata/dummy-process"}}, true, true},
-		{"dodgy binary", DummyServer{}, td, Job{Name: "test", Duration: 5, bin: binary{Path: "/this-binary-hopefully-wont-exist"}}, true, true},
-		{"dodgy rpc", DummyServer{}, td, Job{Name: "test", Duration: 1, bin: binary{Path: "testdata/dummy-process"}}, false, true},
+		{"happy path", DummyServer{}, td, &Job{Name: "test", Duration: 1, bin: binary{Path: "testdata/dummy-process"}}, true, false},
+		{"dodgy log dir", DummyServer{}, "/", &Job{Name: "test", Duration: 1, bin: binary{Path: "testdata/dummy-process"}}, true, true},
+		{"dodgy binary", DummyServer{}, td, &Job{Name: "test", Duration: 5, bin: binary{Path: "/this-binary-hopefully-wont-exist"}}, true, true},
+		{"dodgy rpc", DummyServer{}, td, &Job{Name: "test", Duration: 1, bin: binary{Path: "testdata/dummy-process"}}, false, true},
+		{"schedule exits early", DummyServer{}, td, &Job{Name: "test", Duration: 5, bin: binary{Path: "/bin/true"}}, true, true},
 
 		// Erroring requests *shouldn't* chuck an error
-		{"erroring request", DummyServer{err: true}, td, Job{Name: "test", Duration: 1, bin: binary{Path: "testdata/dummy-process"}, dropRPCErrors: true}, true, false},
+		{"erroring request", DummyServer{err: true, calls: new(int64)}, td, &Job{Name: "test", Duration: 1, bin: binary{Path: "testdata/dummy-process"}, dropRPCErrors: true}, true, false},
 	} {
 		t.Run(test.name, func(t *testing.T) {
 			expoBackoff = backoff.NewExponentialBackOff()
@@ -269,9 +300,9 @@ func TestJob_Start(t *testing.T) {
 			logDir = &test.logDir
 
 			RPCCommand = "DummyServer.Run"
-			dummyServerCalls = 0
+			atomic.StoreInt64(&dummyServerCalls, 0)
 			if test.name == "dodgy rpc" {
-				dummyServerCalls++
+				atomic.AddInt64(&dummyServerCalls, 1)
 			}
 
 			if test.doRPC {
@@ -287,7 +318,11 @@ func TestJob_Start(t *testing.T) {
 				go s.Accept(l)
 			}
 
-			err := test.job.Start(make(chan golo.Output))
+			// Schedules would serve RPC on the address they're given; here
+			// the test's server stands in for them
+			test.job.rpc = rpcAddr{network: "tcp", address: golo.RPCAddr}
+
+			err := test.job.Start(discardSink{})
 			if test.expectError && err == nil {
 				t.Errorf("expected error")
 			}
@@ -299,6 +334,52 @@ func TestJob_Start(t *testing.T) {
 	}
 }
 
+func TestJob_Cancel(t *testing.T) {
+	err := compileDummyBinary(t)
+	if err != nil {
+		panic(err)
+	}
+
+	expoBackoff = backoff.NewExponentialBackOff()
+	expoBackoff.MaxElapsedTime = time.Millisecond
+
+	logDir = &td
+	RPCCommand = "DummyServer.Run"
+
+	l, err := net.Listen("tcp", golo.RPCAddr)
+	if err != nil {
+		t.Fatalf("unexpected error starting server: %+v", err)
+	}
+
+	defer l.Close()
+
+	s := rpc.NewServer()
+	s.Register(DummyServer{})
+	go s.Accept(l)
+
+	j := Job{Name: "test", Duration: 60, bin: binary{Path: "testdata/dummy-process"}, stop: make(chan struct{}), rpc: rpcAddr{network: "tcp", address: golo.RPCAddr}}
+
+	go func() {
+		time.Sleep(time.Second)
+		j.Cancel()
+	}()
+
+	start := time.Now()
+
+	err = j.Start(discardSink{})
+	if err != nil {
+		t.Errorf("unexpected error %+v", err)
+	}
+
+	if time.Since(start) > 10*time.Second {
+		t.Errorf("job ran for %s after being cancelled", time.Since(start))
+	}
+
+	if !j.isCancelled() {
+		t.Errorf("expected job to be cancelled")
+	}
+}
+
 func TestJob_Tail(t *testing.T) {
 	output := `{"sequenceID":"abc123","url":"http://example.com/","method":"GET","status":200,"size":5252,"timestamp":"2018-07-28T14:19:16.343573885+01:00","duration":1000,"error":null}`
 
@@ -314,38 +395,43 @@ func TestJob_Tail(t *testing.T) {
 		Error:      nil,
 	}
 
+	tagged := strings.Replace(output, `{`, `{"scenario":"search",`, 1)
+
 	for _, test := range []struct {
 		name        string
 		stdout      string
 		stderr      string
-		expect      golo.Output
+		scenarios   []Scenario
+		expect      Output
 		expectError bool
 	}{
-		{"valid output", output, "", expect, false},
-		//{"invalid output", "{{", "", golo.Output{}, true},
+		{"valid output", output, "", nil, Output{Output: expect}, false},
+		{"single scenario", output, "", []Scenario{{Name: "browse"}}, Output{Output: expect, Scenario: "browse"}, false},
+		{"several scenarios", output, "", []Scenario{{Name: "browse"}, {Name: "search"}}, Output{Output: expect}, false},
+		{"scenario from schedule", tagged, "", []Scenario{{Name: "browse"}, {Name: "search"}}, Output{Output: expect, Scenario: "search"}, false},
+		{"stderr", output, "panic: oh no", nil, Output{Output: expect}, false},
+		{"several lines", output + "\n" + tagged, "", nil, Output{Output: expect}, false},
+		{"invalid output", "{{", "", nil, Output{}, true},
 	} {
 		t.Run(test.name, func(t *testing.T) {
 			j := Job{
-				logfile:    new(bytes.Buffer),
-				errfile:    new(bytes.Buffer),
-				stdout:     bufio.NewReader(strings.NewReader(test.stdout)),
-				stderr:     bufio.NewReader(strings.NewReader(test.stderr)),
-				outputChan: make(chan golo.Output, 1),
+				Scenarios: test.scenarios,
+				logfile:   new(bytes.Buffer),
+				errfile:   new(bytes.Buffer),
+				stdout:    bufio.NewReader(strings.NewReader(test.stdout)),
+				stderr:    bufio.NewReader(strings.NewReader(test.stderr)),
 			}
 
-			go func() {
-				// Let buffers fill a bit
-				time.Sleep(2 * time.Second)
-				j.complete = true
-			}()
+			outputs := make(chan Output, 1)
+			j.sinks = newFanout([]SinkConfig{{Type: "test"}}, []Sink{chanSink(outputs)})
 
-			var output golo.Output
+			first := make(chan Output, 1)
 			go func() {
-				output = <-j.outputChan
+				first <- <-outputs
 
 				// discard the rest
 				for {
-					<-j.outputChan
+					<-outputs
 				}
 			}()
 
@@ -382,6 +468,13 @@ func TestJob_Tail(t *testing.T) {
 				}
 
 				if test.stdout != "" {
+					var output Output
+
+					select {
+					case output = <-first:
+					case <-time.After(time.Second):
+					}
+
 					if !reflect.DeepEqual(output, test.expect) {
 						t.Errorf("expected %+v, received %+v", test.expect, output)
 					}
@@ -405,9 +498,7 @@ func compileDummyBinary(t *testing.T) (err error) {
 
 	go func() {
 		for {
-			l := []byte{}
-
-			l, err = rd.ReadBytes('\n')
+			l, err := rd.ReadBytes('\n')
 			if err != nil {
 				if err == io.EOF {
 					return
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-lo/agent/agent"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// logPollInterval is how often a followed log file is checked
+	// for new lines
+	logPollInterval = 250 * time.Millisecond
+)
+
+// Logs streams the stdout and/or stderr of a job's schedule, as written
+// to disk by openLogFile. When following, Logs waits for new lines until
+// the job finishes, much like `tail -f`
+func (q *Queue) Logs(l *agent.LogsRequest, stream agent.Agent_LogsServer) (err error) {
+	q.mutex.Lock()
+	j, ok := q.jobs[l.Id]
+	q.mutex.Unlock()
+
+	if !ok {
+		return status.Errorf(codes.NotFound, "no such job %q", l.Id)
+	}
+
+	done := func() bool {
+		q.mutex.Lock()
+		defer q.mutex.Unlock()
+
+		return j.state != agent.JobStatus_QUEUED && j.state != agent.JobStatus_RUNNING
+	}
+
+	streams := []agent.LogsRequest_Stream{l.Stream}
+	if l.Stream == agent.LogsRequest_BOTH {
+		streams = []agent.LogsRequest_Stream{agent.LogsRequest_STDOUT, agent.LogsRequest_STDERR}
+	}
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	lines := make(chan *agent.LogLine)
+	errs := make(chan error, len(streams))
+
+	wg := new(sync.WaitGroup)
+	for _, s := range streams {
+		wg.Add(1)
+
+		go func(s agent.LogsRequest_Stream) {
+			defer wg.Done()
+
+			errs <- tailLog(ctx, j.logPath(s), s, l.Follow, done, lines)
+		}(s)
+	}
+
+	go func() {
+		wg.Wait()
+		close(lines)
+		close(errs)
+	}()
+
+	for line := range lines {
+		err = stream.Send(line)
+		if err != nil {
+			return
+		}
+	}
+
+	for err = range errs {
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// tailLog sends each line of the file at path to lines. When following,
+// tailLog waits for the file to appear and for new lines to be written to
+// it, stopping once done returns true and the file has been read to the end
+func tailLog(ctx context.Context, path string, s agent.LogsRequest_Stream, follow bool, done func() bool, lines chan<- *agent.LogLine) (err error) {
+	var f *os.File
+	for {
+		f, err = os.Open(path)
+		if err == nil {
+			break
+		}
+
+		if !os.IsNotExist(err) {
+			return
+		}
+
+		if !follow || done() {
+			return nil
+		}
+
+		err = wait(ctx, logPollInterval)
+		if err != nil {
+			return
+		}
+	}
+
+	defer f.Close()
+
+	send := func(line string) error {
+		select {
+		case lines <- &agent.LogLine{Stream: s, Line: line}:
+			return nil
+
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	var (
+		r        = bufio.NewReader(f)
+		partial  string
+		finished bool
+	)
+
+	for {
+		var line string
+
+		line, err = r.ReadString('\n')
+		partial += line
+
+		if err == nil {
+			err = send(strings.TrimSuffix(partial, "\n"))
+			if err != nil {
+				return
+			}
+
+			partial = ""
+
+			continue
+		}
+
+		if err != io.EOF {
+			return
+		}
+
+		// Only stop following once the file has been read to the end
+		// *after* the job has finished, so nothing written in the
+		// meantime is missed
+		if !follow || finished {
+			break
+		}
+
+		finished = done()
+
+		err = wait(ctx, logPollInterval)
+		if err != nil {
+			return
+		}
+	}
+
+	if partial != "" {
+		return send(partial)
+	}
+
+	return nil
+}
+
+// wait sleeps for d, returning early with an error if ctx is done first
+func wait(ctx context.Context, d time.Duration) error {
+	select {
+	case <-time.After(d):
+		return nil
+
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// logDir returns the directory this job's files are written to. It's
+// named for the job's ID, rather than its name, so that jobs of the
+// same name, run one after another or side by side, don't share files
+func (j *Job) logDir() string {
+	return filepath.Join(*logDir, j.ID)
+}
+
+// logPath returns the path on disk of the log file of a stream
+// of this job's schedule
+func (j *Job) logPath(s agent.LogsRequest_Stream) string {
+	f := "out.log"
+	if s == agent.LogsRequest_STDERR {
+		f = "err.log"
+	}
+
+	return filepath.Join(j.logDir(), f)
+}
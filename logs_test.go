@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-lo/agent/agent"
+	"google.golang.org/grpc"
+)
+
+type dummyLogsServer struct {
+	grpc.ServerStream
+
+	lines []*agent.LogLine
+}
+
+func (s *dummyLogsServer) Send(l *agent.LogLine) error {
+	s.lines = append(s.lines, l)
+
+	return nil
+}
+
+func (s *dummyLogsServer) Context() context.Context {
+	return context.Background()
+}
+
+func TestQueue_Logs(t *testing.T) {
+	logDir = &td
+
+	q := NewQueue(discardSink{})
+	r, _ := q.Create(context.Background(), &agent.Payload{
+		Job: &agent.Job{
+			Name:     "logs-test",
+			Duration: 1,
+			Binary:   "testdata/dummy-process",
+		},
+	})
+
+	j := q.jobs[r.Id]
+	j.state = agent.JobStatus_COMPLETED
+
+	os.MkdirAll(j.logDir(), os.ModePerm)
+	ioutil.WriteFile(j.logPath(agent.LogsRequest_STDOUT), []byte("out 1\nout 2\n"), 0644)
+	ioutil.WriteFile(j.logPath(agent.LogsRequest_STDERR), []byte("err 1\nerr 2\nerr 3"), 0644)
+
+	for _, test := range []struct {
+		name        string
+		request     *agent.LogsRequest
+		expect      int
+		expectError bool
+	}{
+		{"stdout", &agent.LogsRequest{Id: r.Id, Stream: agent.LogsRequest_STDOUT}, 2, false},
+		{"stderr, with a partial line", &agent.LogsRequest{Id: r.Id, Stream: agent.LogsRequest_STDERR}, 3, false},
+		{"both", &agent.LogsRequest{Id: r.Id}, 5, false},
+		{"following a finished job", &agent.LogsRequest{Id: r.Id, Follow: true}, 5, false},
+		{"unknown job", &agent.LogsRequest{Id: "nonsuch"}, 0, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			s := new(dummyLogsServer)
+
+			err := q.Logs(test.request, s)
+			if test.expectError && err == nil {
+				t.Errorf("expected error")
+			}
+
+			if !test.expectError && err != nil {
+				t.Errorf("unexpected error %+v", err)
+			}
+
+			if test.expect != len(s.lines) {
+				t.Errorf("expected %d lines, received %d", test.expect, len(s.lines))
+			}
+		})
+	}
+}
+
+func TestTailLog_Follow(t *testing.T) {
+	path := filepath.Join(td, "follow.log")
+	os.Remove(path)
+
+	finished := make(chan bool)
+	done := func() bool {
+		select {
+		case <-finished:
+			return true
+		default:
+			return false
+		}
+	}
+
+	lines := make(chan *agent.LogLine)
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- tailLog(context.Background(), path, agent.LogsRequest_STDOUT, true, done, lines)
+		close(lines)
+	}()
+
+	// The file doesn't exist until the job starts
+	time.Sleep(logPollInterval)
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	defer f.Close()
+
+	go func() {
+		for i := 0; i < 3; i++ {
+			fmt.Fprintf(f, "line %d\n", i)
+			time.Sleep(logPollInterval)
+		}
+
+		close(finished)
+	}()
+
+	var received []string
+	for l := range lines {
+		received = append(received, l.Line)
+	}
+
+	err = <-errs
+	if err != nil {
+		t.Errorf("unexpected error %+v", err)
+	}
+
+	if len(received) != 3 {
+		t.Fatalf("expected 3 lines, received %d: %v", len(received), received)
+	}
+
+	for i, l := range received {
+		expect := fmt.Sprintf("line %d", i)
+		if expect != l {
+			t.Errorf("expected %q, received %q", expect, l)
+		}
+	}
+}
+
+func TestJob_OpenLogFile(t *testing.T) {
+	logDir = &td
+
+	first := &Job{ID: "open-log-file", Name: "logs-test"}
+	second := &Job{ID: "open-log-file-again", Name: "logs-test"}
+
+	if first.logPath(agent.LogsRequest_STDOUT) == second.logPath(agent.LogsRequest_STDOUT) {
+		t.Errorf("expected jobs of the same name to log to different files")
+	}
+
+	os.MkdirAll(first.logDir(), os.ModePerm)
+	ioutil.WriteFile(first.logPath(agent.LogsRequest_STDOUT), []byte("stale 1\nstale 2\n"), 0644)
+
+	err := first.openLogFile()
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	first.logline([]byte("fresh"))
+
+	b, _ := ioutil.ReadFile(first.logPath(agent.LogsRequest_STDOUT))
+	if "fresh\n" != string(b) {
+		t.Errorf("expected %q, received %q", "fresh\n", string(b))
+	}
+}
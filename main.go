@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/go-lo/agent/agent"
+	"google.golang.org/grpc"
+)
+
+var (
+	logDir     = flag.String("logs", "/var/log/go-lo", "directory to write schedule stdout/stderr to")
+	listenAddr = flag.String("listen", ":8081", "address to serve the agent gRPC API on")
+)
+
+func main() {
+	flag.Parse()
+
+	err := subreaper()
+	if err != nil {
+		log.Printf("unable to reap orphaned schedule processes: %+v", err)
+	}
+
+	if *trustedKeysFile != "" {
+		err = loadTrustedKeys(*trustedKeysFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *metricsAddr != "" {
+		go func() {
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", promMetrics)
+
+			log.Fatal(http.ListenAndServe(*metricsAddr, mux))
+		}()
+	}
+
+	collector, err := newSpool(newJSONSink(os.Stdout), filepath.Join(*logDir, "spool"), *spoolBuffer)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	q := NewQueue(collector)
+	go q.Run()
+
+	l, err := net.Listen("tcp", *listenAddr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	s := grpc.NewServer()
+	agent.RegisterAgentServer(s, q)
+
+	log.Fatal(s.Serve(l))
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// userPool limits the number of users a job runs concurrently. It works
+// like a semaphore, except that its size can change while users are
+// running: growing lets more users in straight away, while shrinking
+// retires users as they finish what they're doing.
+//
+// Each user is given an ID. The IDs of users which leave the pool are
+// given to those which join it later, so that IDs stay below the most
+// users the pool has ever run at once
+type userPool struct {
+	mutex   sync.Mutex
+	cond    *sync.Cond
+	size    int
+	active  int
+	stopped int
+	next    int
+	free    []int
+	closed  bool
+	done    chan struct{}
+}
+
+func newUserPool(size int) (p *userPool) {
+	p = &userPool{
+		size: size,
+		done: make(chan struct{}),
+	}
+
+	p.cond = sync.NewCond(&p.mutex)
+
+	return
+}
+
+// acquire blocks until there is space in the pool for another user,
+// returning its ID, or false if the pool is closed in the meantime
+func (p *userPool) acquire() (id int, ok bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for !p.closed && p.active+p.stopped >= p.size {
+		p.cond.Wait()
+	}
+
+	if p.closed {
+		return
+	}
+
+	p.active++
+
+	if len(p.free) > 0 {
+		id, p.free = p.free[len(p.free)-1], p.free[:len(p.free)-1]
+	} else {
+		id = p.next
+		p.next++
+	}
+
+	return id, true
+}
+
+// release lets the user id leave the pool, freeing up its space,
+// and its ID, for another
+func (p *userPool) release(id int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.active--
+	p.free = append(p.free, id)
+	p.cond.Broadcast()
+}
+
+// stop releases a user without freeing up its space in the pool,
+// so that no user takes its place
+func (p *userPool) stop() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.active--
+	p.stopped++
+}
+
+// retire releases the user id if the pool has more running than it
+// allows, returning true if so. Users call retire between calls, which
+// lets a pool shrink without interrupting anything
+func (p *userPool) retire(id int) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.active+p.stopped <= p.size {
+		return false
+	}
+
+	p.active--
+	p.free = append(p.free, id)
+	p.cond.Broadcast()
+
+	return true
+}
+
+// sleep waits for d, returning false early if the pool is
+// closed in the meantime
+func (p *userPool) sleep(d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+
+	select {
+	case <-time.After(d):
+		return true
+
+	case <-p.done:
+		return false
+	}
+}
+
+// resize sets the number of users the pool allows to run at once
+func (p *userPool) resize(size int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.size = size
+	p.cond.Broadcast()
+}
+
+// users returns the number of users currently running
+func (p *userPool) users() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.active
+}
+
+// close stops the pool from letting any more users in, and releases
+// anything waiting to acquire
+func (p *userPool) close() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.closed {
+		return
+	}
+
+	p.closed = true
+	close(p.done)
+	p.cond.Broadcast()
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUserPool(t *testing.T) {
+	p := newUserPool(2)
+
+	a, aok := p.acquire()
+	b, bok := p.acquire()
+	if !aok || !bok {
+		t.Fatalf("expected to acquire up to pool size")
+	}
+
+	acquired := make(chan bool)
+	go func() {
+		_, ok := p.acquire()
+		acquired <- ok
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatalf("expected full pool to block")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	t.Run("growing lets users in", func(t *testing.T) {
+		p.resize(3)
+
+		if !<-acquired {
+			t.Errorf("expected to acquire")
+		}
+
+		if p.users() != 3 {
+			t.Errorf("expected 3 users, received %d", p.users())
+		}
+	})
+
+	t.Run("shrinking holds users back", func(t *testing.T) {
+		p.resize(1)
+		p.release(a)
+		p.release(b)
+
+		go func() {
+			_, ok := p.acquire()
+			acquired <- ok
+		}()
+
+		select {
+		case <-acquired:
+			t.Errorf("expected shrunk pool to block")
+		case <-time.After(10 * time.Millisecond):
+		}
+	})
+
+	t.Run("users retire when the pool shrinks", func(t *testing.T) {
+		p.resize(0)
+
+		if !p.retire(2) {
+			t.Errorf("expected user to retire")
+		}
+
+		if p.retire(2) {
+			t.Errorf("unexpected retirement from an empty pool")
+		}
+	})
+
+	t.Run("closing releases waiters", func(t *testing.T) {
+		p.close()
+
+		if <-acquired {
+			t.Errorf("expected acquire to fail on closed pool")
+		}
+
+		if _, ok := p.acquire(); ok {
+			t.Errorf("expected acquire to fail on closed pool")
+		}
+	})
+}
+
+func TestUserPool_Stop(t *testing.T) {
+	p := newUserPool(2)
+	defer p.close()
+
+	p.acquire()
+	p.acquire()
+	p.stop()
+
+	if p.users() != 1 {
+		t.Errorf("expected 1 user, received %d", p.users())
+	}
+
+	acquired := make(chan bool)
+	go func() {
+		_, ok := p.acquire()
+		acquired <- ok
+	}()
+
+	select {
+	case <-acquired:
+		t.Errorf("expected a stopped user to keep its space in the pool")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	p.resize(3)
+
+	if !<-acquired {
+		t.Errorf("expected to acquire")
+	}
+}
+
+func TestUserPool_IDs(t *testing.T) {
+	p := newUserPool(3)
+	defer p.close()
+
+	for expect := 0; expect < 3; expect++ {
+		id, _ := p.acquire()
+		if expect != id {
+			t.Errorf("expected %d, received %d", expect, id)
+		}
+	}
+
+	// Users which leave free up their IDs for those which join
+	p.release(1)
+	p.resize(1)
+
+	if !p.retire(0) {
+		t.Fatalf("expected user to retire")
+	}
+
+	p.resize(3)
+
+	ids := make(map[int]bool)
+	for i := 0; i < 2; i++ {
+		id, _ := p.acquire()
+		ids[id] = true
+	}
+
+	if !ids[0] || !ids[1] {
+		t.Errorf("expected IDs 0 and 1 to be reused, received %v", ids)
+	}
+}
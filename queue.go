@@ -0,0 +1,932 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-lo/agent/agent"
+	"github.com/gofrs/uuid"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	capacity = flag.Int("capacity", 0, "total users shared jobs may run at once; with 0, shared jobs run alone like exclusive ones")
+	retain   = flag.Int("retain", 100, "finished jobs to keep the status of; past this, the oldest are forgotten")
+)
+
+// Queue implements agent.AgentServer. It accepts jobs from calls
+// to Create, and starts them in the order in which they were received.
+// Exclusive jobs run alone; shared jobs run alongside each other, so
+// long as their reservations fit within capacity
+type Queue struct {
+	collector Sink
+
+	mutex     sync.Mutex
+	cond      *sync.Cond
+	jobs      map[string]*Job
+	pending   []*Job
+	running   map[*Job]bool
+	finished  []*Job
+	reserved  int
+	exclusive bool
+	legacy    bool
+}
+
+// NewQueue returns a Queue whose jobs send their output to collector,
+// unless they have sinks of their own. Jobs wont be run until Queue.Run
+// is called
+func NewQueue(collector Sink) (q *Queue) {
+	q = &Queue{
+		collector: collector,
+		jobs:      make(map[string]*Job),
+		pending:   make([]*Job, 0),
+		running:   make(map[*Job]bool),
+	}
+
+	q.cond = sync.NewCond(&q.mutex)
+
+	return
+}
+
+// Create validates a Payload, turns it into a Job and enqueues it. The
+// Response contains the ID of the new job, and its position in the queue;
+// the number of jobs queued or running ahead of it
+func (q *Queue) Create(ctx context.Context, p *agent.Payload) (r *agent.Response, err error) {
+	r = new(agent.Response)
+
+	j, err := jobFromPayload(p)
+	if err != nil {
+		r.Error = true
+		r.Output = err.Error()
+
+		return r, nil
+	}
+
+	if j.bin.URL != "" {
+		go prefetch(j.bin)
+	}
+
+	if j.Feeder.Path != "" {
+		j.feederLoad = preload(j.Feeder)
+	}
+
+	position := q.enqueue(j)
+
+	r.Id = j.ID
+	r.Output = strconv.Itoa(position)
+
+	return
+}
+
+// Status returns the current state of a job
+func (q *Queue) Status(ctx context.Context, id *agent.JobID) (js *agent.JobStatus, err error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	j, ok := q.jobs[id.Id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no such job %q", id.Id)
+	}
+
+	return j.status(), nil
+}
+
+// List returns the state of every job the queue knows about, whether
+// queued, running or finished, in the order they were created
+func (q *Queue) List(ctx context.Context, _ *agent.ListRequest) (jl *agent.JobList, err error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	jobs := make([]*Job, 0, len(q.jobs))
+	for _, j := range q.jobs {
+		jobs = append(jobs, j)
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].queued.Before(jobs[j].queued)
+	})
+
+	jl = &agent.JobList{
+		Jobs: make([]*agent.JobStatus, len(jobs)),
+	}
+
+	for i, j := range jobs {
+		jl.Jobs[i] = j.status()
+	}
+
+	return
+}
+
+// Cancel stops a job. Queued jobs are removed from the queue, and
+// running jobs are stopped. Either way, the job is marked as cancelled
+func (q *Queue) Cancel(ctx context.Context, id *agent.JobID) (r *agent.Response, err error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	r = &agent.Response{
+		Id: id.Id,
+	}
+
+	j, ok := q.jobs[id.Id]
+	if !ok {
+		r.Error = true
+		r.Output = fmt.Sprintf("no such job %q", id.Id)
+
+		return
+	}
+
+	switch j.state {
+	case agent.JobStatus_QUEUED:
+		for i, p := range q.pending {
+			if p == j {
+				q.pending = append(q.pending[:i], q.pending[i+1:]...)
+
+				break
+			}
+		}
+
+		j.Cancel()
+		j.watchers.close()
+		j.state = agent.JobStatus_CANCELLED
+		q.archive(j)
+
+		// The job may have been holding up the rest of the queue
+		q.cond.Broadcast()
+
+	case agent.JobStatus_RUNNING:
+		// The job is marked as cancelled by Run once it has stopped
+		j.Cancel()
+
+	default:
+		r.Error = true
+		r.Output = fmt.Sprintf("job %q has already finished", id.Id)
+
+		return
+	}
+
+	r.Output = "cancelled"
+
+	return
+}
+
+// Watch streams the results of a running job as they're read from
+// its schedule, until the job finishes or the client goes away. A
+// subscriber which can't keep up misses results rather than slowing the
+// job down
+func (q *Queue) Watch(w *agent.WatchRequest, stream agent.Agent_WatchServer) (err error) {
+	if w.Sample < 0 || w.Sample > 1 {
+		return status.Errorf(codes.InvalidArgument, "sample must be between 0 and 1")
+	}
+
+	q.mutex.Lock()
+	j, ok := q.jobs[w.Id]
+	q.mutex.Unlock()
+
+	if !ok {
+		return status.Errorf(codes.NotFound, "no such job %q", w.Id)
+	}
+
+	s := j.watchers.subscribe(w.Sample)
+	defer j.watchers.unsubscribe(s)
+
+	for {
+		select {
+		case o, ok := <-s.c:
+			if !ok {
+				return
+			}
+
+			err = stream.Send(resultProto(o))
+			if err != nil {
+				return
+			}
+
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+// Run will take jobs from the queue, as there's room for them, and
+// start them. It blocks forever, and so should be run in a goroutine
+func (q *Queue) Run() {
+	for {
+		j := q.next()
+
+		go func() {
+			err := j.Start(q.collector)
+			if err != nil {
+				log.Printf("job %s: %+v", j.ID, err)
+			}
+
+			q.finish(j, err)
+		}()
+	}
+}
+
+func (q *Queue) enqueue(j *Job) (position int) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	position = len(q.pending) + len(q.running)
+
+	j.state = agent.JobStatus_QUEUED
+	j.queued = time.Now()
+
+	q.jobs[j.ID] = j
+	q.pending = append(q.pending, j)
+	q.cond.Signal()
+
+	return
+}
+
+// next waits for the job at the front of the queue to be admitted,
+// and takes it off the queue. Jobs are admitted strictly in order, so
+// a large job waiting for room isn't overtaken, and starved, by
+// smaller ones
+func (q *Queue) next() (j *Job) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for len(q.pending) == 0 || !q.admits(q.pending[0]) {
+		q.cond.Wait()
+	}
+
+	j, q.pending = q.pending[0], q.pending[1:]
+
+	q.running[j] = true
+	q.reserved += j.reservation()
+	q.exclusive = !j.Shared
+	q.legacy = q.legacy || j.Legacy
+
+	j.state = agent.JobStatus_RUNNING
+	j.started = time.Now()
+
+	return
+}
+
+// admits returns whether j can start alongside the jobs already
+// running. Callers must hold the queue's lock
+func (q *Queue) admits(j *Job) bool {
+	switch {
+	case len(q.running) == 0:
+		return true
+	case q.exclusive, !j.Shared:
+		return false
+	case j.Legacy && q.legacy:
+		// Legacy schedules all listen on the same address
+		return false
+	default:
+		return q.reserved+j.reservation() <= *capacity
+	}
+}
+
+// reservation is how much of the agent's capacity a job takes up: its
+// peak number of users or, for jobs with a rate, the number of calls
+// it can have in flight
+func (j *Job) reservation() int {
+	switch {
+	case j.Rate > 0 && j.MaxInFlight == 0:
+		return DefaultMaxInFlight
+	case j.Rate > 0:
+		return j.MaxInFlight
+	case len(j.Stages) > 0:
+		return j.peakUsers()
+	case j.Users == 0:
+		return DefaultUserCount
+	}
+
+	return j.Users
+}
+
+func (q *Queue) finish(j *Job, err error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	delete(q.running, j)
+	q.reserved -= j.reservation()
+	if j.Legacy {
+		q.legacy = false
+	}
+	if len(q.running) == 0 {
+		q.exclusive = false
+	}
+
+	q.cond.Broadcast()
+
+	j.watchers.close()
+
+	switch {
+	case j.isCancelled():
+		j.state = agent.JobStatus_CANCELLED
+	case err != nil:
+		j.state = agent.JobStatus_FAILED
+		j.err = err
+	default:
+		j.state = agent.JobStatus_COMPLETED
+	}
+
+	q.archive(j)
+}
+
+// archive records that j has finished. Finished jobs are only kept for
+// their status, and only the most recent -retain of them. Callers must
+// hold the queue's lock
+func (q *Queue) archive(j *Job) {
+	j.finished = time.Now()
+	j.release()
+
+	q.finished = append(q.finished, j)
+	for len(q.finished) > *retain {
+		delete(q.jobs, q.finished[0].ID)
+		q.finished = q.finished[1:]
+	}
+}
+
+// release drops what a finished job no longer needs, keeping only what
+// its status reports. Its summary, with histograms of every url, is
+// reduced to the summary reported. Calls and output still trickling in
+// from the schedule are ignored
+func (j *Job) release() {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	if j.summary != nil {
+		j.final = summaryProto(j.summary)
+	}
+
+	j.summary.close()
+	j.feeder.close()
+	j.feederLoad = nil
+}
+
+// status returns a JobStatus for j. Callers must hold the queue's lock
+func (j *Job) status() (js *agent.JobStatus) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	js = &agent.JobStatus{
+		Id: j.ID,
+		Job: &agent.Job{
+			Name:        j.Name,
+			Users:       uint32(j.Users),
+			Duration:    uint32(j.Duration),
+			Container:   j.Container,
+			Binary:      j.Binary,
+			Sha256:      j.SHA256,
+			Signature:   j.Signature,
+			Args:        j.Args,
+			Env:         j.Env,
+			Workdir:     j.Workdir,
+			Shared:      j.Shared,
+			Connections: uint32(j.Connections),
+			Balance:     balanceProto(j.Balance),
+			Transport:   j.Transport,
+			Scenarios:   scenariosProto(j.Scenarios),
+			Params:      j.Params,
+			Feeder:      feederProto(j.Feeder),
+			Thresholds:  thresholdsProto(j.Thresholds),
+			Sinks:       sinksProto(j.Sinks),
+			Legacy:      j.Legacy,
+			Runtime:     j.Runtime,
+			Stages:      make([]*agent.Stage, len(j.Stages)),
+			Rate:        j.Rate,
+			MaxInFlight: uint32(j.MaxInFlight),
+			Pacing:      uint32(j.Pacing),
+			ThinkTime:   thinkTimeProto(j.ThinkTime),
+			GracePeriod: uint32(j.GracePeriod),
+			StopTimeout: uint32(j.StopTimeout),
+			Limits:      limitsProto(j.Limits),
+		},
+		State:           j.state,
+		Items:           uint64(atomic.LoadInt64(&j.items)),
+		Dropped:         uint64(atomic.LoadInt64(&j.dropped)),
+		Exited:          j.exitPhase,
+		OomKilled:       j.oomKilled,
+		ConnectionCalls: j.conns.counts(),
+		Summary:         j.final,
+		Thresholds:      thresholdResults(j.thresholds),
+		Sinks:           j.sinks.status(),
+		Queued:          timestampProto(j.queued),
+		Started:         timestampProto(j.started),
+		Finished:        timestampProto(j.finished),
+	}
+
+	if j.final == nil {
+		js.Summary = summaryProto(j.summary)
+	}
+
+	for i, s := range j.Stages {
+		js.Job.Stages[i] = &agent.Stage{
+			Users:    uint32(s.Users),
+			Duration: uint32(s.Duration),
+		}
+	}
+
+	if j.pool != nil {
+		js.Users = uint32(j.pool.users())
+	}
+
+	if j.err != nil {
+		js.Error = j.err.Error()
+	}
+
+	return
+}
+
+// scenarios converts the Scenarios from a payload into the Scenarios
+// of a Job, ensuring each has a name, and that names are unique
+func scenarios(ps []*agent.Scenario) (s []Scenario, err error) {
+	names := make(map[string]bool)
+
+	for _, p := range ps {
+		switch {
+		case p.Name == "":
+			return nil, fmt.Errorf("job scenario is missing a name")
+		case names[p.Name]:
+			return nil, fmt.Errorf("job has more than one scenario called %q", p.Name)
+		}
+
+		names[p.Name] = true
+
+		s = append(s, Scenario{
+			Name:   p.Name,
+			Method: p.Method,
+			Weight: int(p.Weight),
+		})
+	}
+
+	return
+}
+
+// scenariosProto is the inverse of scenarios
+func scenariosProto(s []Scenario) (ps []*agent.Scenario) {
+	for _, sc := range s {
+		ps = append(ps, &agent.Scenario{
+			Name:   sc.Name,
+			Method: sc.Method,
+			Weight: uint32(sc.Weight),
+		})
+	}
+
+	return
+}
+
+// thinkTime converts a ThinkTime from a payload into the
+// ThinkTime of a Job
+func thinkTime(t *agent.ThinkTime) ThinkTime {
+	if t.GetDistribution() == agent.ThinkTime_NONE {
+		return ThinkTime{}
+	}
+
+	return ThinkTime{
+		Distribution: strings.ToLower(t.Distribution.String()),
+		Min:          int64(t.Min),
+		Max:          int64(t.Max),
+		Mean:         int64(t.Mean),
+	}
+}
+
+// thinkTimeProto is the inverse of thinkTime
+func thinkTimeProto(t ThinkTime) *agent.ThinkTime {
+	if t.Distribution == "" {
+		return nil
+	}
+
+	return &agent.ThinkTime{
+		Distribution: agent.ThinkTime_Distribution(agent.ThinkTime_Distribution_value[strings.ToUpper(t.Distribution)]),
+		Min:          uint32(t.Min),
+		Max:          uint32(t.Max),
+		Mean:         uint32(t.Mean),
+	}
+}
+
+// balance converts the Balance of a payload into the
+// Balance of a Job
+func balance(b agent.Job_Balance) string {
+	return strings.Replace(strings.ToLower(b.String()), "_", "-", -1)
+}
+
+// balanceProto is the inverse of balance
+func balanceProto(b string) agent.Job_Balance {
+	return agent.Job_Balance(agent.Job_Balance_value[strings.Replace(strings.ToUpper(b), "-", "_", -1)])
+}
+
+// feederConfig converts the Feeder from a payload into the
+// Feeder of a Job
+func feederConfig(f *agent.Feeder) Feeder {
+	if f == nil {
+		return Feeder{}
+	}
+
+	return Feeder{
+		Path:      f.Path,
+		Format:    f.Format,
+		Strategy:  strings.ToLower(f.Strategy.String()),
+		Exhausted: strings.Replace(strings.ToLower(f.Exhausted.String()), "_", "-", -1),
+	}
+}
+
+// feederProto is the inverse of feederConfig
+func feederProto(f Feeder) *agent.Feeder {
+	if f.Path == "" {
+		return nil
+	}
+
+	return &agent.Feeder{
+		Path:      f.Path,
+		Format:    f.Format,
+		Strategy:  agent.Feeder_Strategy(agent.Feeder_Strategy_value[strings.ToUpper(f.Strategy)]),
+		Exhausted: agent.Feeder_Exhausted(agent.Feeder_Exhausted_value[strings.Replace(strings.ToUpper(f.Exhausted), "-", "_", -1)]),
+	}
+}
+
+// thresholds converts the Thresholds from a payload into the
+// Thresholds of a Job
+func thresholds(ps []*agent.Threshold) (t []Threshold) {
+	for _, p := range ps {
+		t = append(t, Threshold{
+			Expression: p.Expression,
+			URL:        p.Url,
+			Abort:      p.Abort,
+			AbortAfter: int64(p.AbortAfter),
+		})
+	}
+
+	return
+}
+
+// thresholdsProto is the inverse of thresholds
+func thresholdsProto(t []Threshold) (ps []*agent.Threshold) {
+	for _, th := range t {
+		ps = append(ps, thresholdProto(th))
+	}
+
+	return
+}
+
+func thresholdProto(t Threshold) *agent.Threshold {
+	return &agent.Threshold{
+		Expression: t.Expression,
+		Url:        t.URL,
+		Abort:      t.Abort,
+		AbortAfter: uint32(t.AbortAfter),
+	}
+}
+
+// thresholdResults checks each of ths
+func thresholdResults(ths []*threshold) (rs []*agent.ThresholdResult) {
+	for _, th := range ths {
+		ok, observed := th.check()
+
+		rs = append(rs, &agent.ThresholdResult{
+			Threshold: thresholdProto(th.Threshold),
+			Passed:    ok,
+			Observed:  th.format(observed),
+		})
+	}
+
+	return
+}
+
+// sinkConfigs converts the Sinks from a payload into the Sinks
+// of a Job, ensuring each is valid
+func sinkConfigs(ps []*agent.Sink) (s []SinkConfig, err error) {
+	for _, p := range ps {
+		c := SinkConfig{
+			Type: p.Type,
+			Path: p.Path,
+			URL:  p.Url,
+		}
+
+		err = c.validate()
+		if err != nil {
+			return nil, err
+		}
+
+		s = append(s, c)
+	}
+
+	return
+}
+
+// sinksProto is the inverse of sinkConfigs
+func sinksProto(s []SinkConfig) (ps []*agent.Sink) {
+	for _, c := range s {
+		ps = append(ps, sinkProto(c))
+	}
+
+	return
+}
+
+func sinkProto(c SinkConfig) *agent.Sink {
+	return &agent.Sink{
+		Type: c.Type,
+		Path: c.Path,
+		Url:  c.URL,
+	}
+}
+
+// limits converts the Limits from a payload into the
+// Limits of a Job
+func limits(l *agent.Limits) Limits {
+	return Limits{
+		CPU:    l.GetCpu(),
+		Memory: int64(l.GetMemory()),
+		Pids:   int64(l.GetPids()),
+	}
+}
+
+// limitsProto is the inverse of limits
+func limitsProto(l Limits) *agent.Limits {
+	if !l.set() {
+		return nil
+	}
+
+	return &agent.Limits{
+		Cpu:    l.CPU,
+		Memory: uint64(l.Memory),
+		Pids:   uint32(l.Pids),
+	}
+}
+
+// summaryProto converts a summary into a Summary, with its
+// breakdown sorted by URL and method
+func summaryProto(s *summary) *agent.Summary {
+	if s == nil {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	sp := &agent.Summary{
+		Total:    statsProto(s, s.total),
+		Requests: make([]*agent.Stats, 0, len(s.requests)),
+	}
+
+	for _, r := range s.requests {
+		sp.Requests = append(sp.Requests, statsProto(s, r))
+	}
+
+	sort.Slice(sp.Requests, func(i, j int) bool {
+		if sp.Requests[i].Url == sp.Requests[j].Url {
+			return sp.Requests[i].Method < sp.Requests[j].Method
+		}
+
+		return sp.Requests[i].Url < sp.Requests[j].Url
+	})
+
+	return sp
+}
+
+// statsProto converts the stats st, of summary s, into Stats
+func statsProto(s *summary, st *stats) (sp *agent.Stats) {
+	sp = &agent.Stats{
+		Url:        st.URL,
+		Method:     st.Method,
+		Count:      uint64(st.Count),
+		Errors:     uint64(st.Errors),
+		ErrorRate:  st.errorRate(),
+		Bytes:      uint64(st.Bytes),
+		Throughput: s.throughput(st),
+		Statuses:   make(map[int32]uint64),
+		Latency: &agent.Latency{
+			P50:  int64(st.latencies.percentile(50)),
+			P90:  int64(st.latencies.percentile(90)),
+			P95:  int64(st.latencies.percentile(95)),
+			P99:  int64(st.latencies.percentile(99)),
+			P999: int64(st.latencies.percentile(99.9)),
+			Max:  int64(st.latencies.max),
+		},
+	}
+
+	for status, count := range st.Statuses {
+		sp.Statuses[int32(status)] = uint64(count)
+	}
+
+	return
+}
+
+// resultProto converts an Output into a Result
+func resultProto(o Output) (r *agent.Result) {
+	r = &agent.Result{
+		SequenceId: o.SequenceID,
+		Url:        o.URL,
+		Method:     o.Method,
+		Status:     int32(o.Status),
+		Size:       int64(o.Size),
+		Timestamp:  timestampProto(o.Timestamp),
+		Duration:   int64(o.Duration),
+		Scenario:   o.Scenario,
+	}
+
+	if o.Error != nil {
+		r.Error = o.Error.Error()
+	}
+
+	return
+}
+
+// timestampProto converts t into a protobuf timestamp, returning nil
+// for the zero time so unset times stay unset
+func timestampProto(t time.Time) *timestamp.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+
+	ts, _ := ptypes.TimestampProto(t)
+
+	return ts
+}
+
+func jobFromPayload(p *agent.Payload) (j *Job, err error) {
+	switch {
+	case p.Job == nil:
+		err = fmt.Errorf("payload is missing a job")
+	case p.Job.Name == "":
+		err = fmt.Errorf("job is missing a name")
+	case strings.ContainsAny(p.Job.Name, `/\`) || strings.Contains(p.Job.Name, ".."):
+		err = fmt.Errorf("job name %q can't contain path separators or ..", p.Job.Name)
+	case p.Job.Runtime == RuntimeContainer && p.Job.Container == "":
+		err = fmt.Errorf("job is missing a container")
+	case p.Job.Runtime == RuntimeLocal && p.Job.Binary == "":
+		err = fmt.Errorf("job is missing a binary")
+	case p.Job.Runtime == "" && p.Job.Container == "" && p.Job.Binary == "":
+		err = fmt.Errorf("job is missing a container")
+	case p.Job.Legacy && p.Job.Transport == TransportGRPC:
+		err = fmt.Errorf("legacy jobs can only use the rpc transport")
+	case p.Job.Rate < 0:
+		err = fmt.Errorf("job rate must be positive")
+	case p.Job.Rate > 0 && (p.Job.Rate < MinRate || p.Job.Rate > MaxRate):
+		err = fmt.Errorf("job rate must be between %g and %g calls a second", MinRate, MaxRate)
+	case p.Job.Rate > 0 && len(p.Job.Stages) > 0:
+		err = fmt.Errorf("job can't have both a rate and stages")
+	case p.Job.ThinkTime.GetDistribution() == agent.ThinkTime_UNIFORM && p.Job.ThinkTime.Max < p.Job.ThinkTime.Min:
+		err = fmt.Errorf("job think time max must be at least min")
+	case p.Job.Limits.GetCpu() < 0:
+		err = fmt.Errorf("job cpu limit must be positive")
+	case p.Job.Rate > 0 && p.Job.Feeder.GetStrategy() == agent.Feeder_UNIQUE:
+		err = fmt.Errorf("job with a rate has no users for a unique feeder to give records to")
+	case p.Job.Rate > 0 && p.Job.Feeder.GetExhausted() == agent.Feeder_STOP_USER:
+		err = fmt.Errorf("job with a rate has no users for an exhausted feeder to stop; use stop-job instead")
+	}
+
+	if err != nil {
+		return
+	}
+
+	stages := make([]Stage, len(p.Job.Stages))
+	for i, s := range p.Job.Stages {
+		stages[i] = Stage{
+			Users:    int(s.Users),
+			Duration: int64(s.Duration),
+		}
+	}
+
+	scenarios, err := scenarios(p.Job.Scenarios)
+	if err != nil {
+		return
+	}
+
+	sinks, err := sinkConfigs(p.Job.Sinks)
+	if err != nil {
+		return
+	}
+
+	transport := p.Job.Transport
+	switch transport {
+	case "":
+		transport = TransportRPC
+	case TransportRPC, TransportGRPC:
+	default:
+		return nil, fmt.Errorf("unknown transport %q", transport)
+	}
+
+	name := p.Job.Runtime
+	if name == "" {
+		name = RuntimeLocal
+		if p.Job.Container != "" {
+			name = RuntimeContainer
+		}
+	}
+
+	r, err := newRuntime(name)
+	if err != nil {
+		return
+	}
+
+	// Only local binaries can be verified, so agents with trusted
+	// keys run nothing else
+	if len(trustedKeys) > 0 && name != RuntimeLocal {
+		return nil, fmt.Errorf("this agent only runs signed local binaries, not %s jobs", name)
+	}
+
+	var bin binary
+	if name == RuntimeLocal {
+		bin, err = newBinary(p.Job.Binary, p.Job.Sha256, p.Job.Signature)
+		if err != nil {
+			return
+		}
+	}
+
+	j = &Job{
+		Name:        p.Job.Name,
+		Users:       int(p.Job.Users),
+		Duration:    int64(p.Job.Duration),
+		Binary:      p.Job.Binary,
+		Runtime:     name,
+		Container:   p.Job.Container,
+		SHA256:      p.Job.Sha256,
+		Signature:   p.Job.Signature,
+		Args:        p.Job.Args,
+		Env:         p.Job.Env,
+		Workdir:     p.Job.Workdir,
+		Shared:      p.Job.Shared,
+		Connections: int(p.Job.Connections),
+		Balance:     balance(p.Job.Balance),
+		Transport:   transport,
+		Scenarios:   scenarios,
+		Params:      p.Job.Params,
+		Feeder:      feederConfig(p.Job.Feeder),
+		Thresholds:  thresholds(p.Job.Thresholds),
+		Sinks:       sinks,
+		Legacy:      p.Job.Legacy,
+		Stages:      stages,
+		Rate:        p.Job.Rate,
+		MaxInFlight: int(p.Job.MaxInFlight),
+		Pacing:      int64(p.Job.Pacing),
+		ThinkTime:   thinkTime(p.Job.ThinkTime),
+		GracePeriod: int64(p.Job.GracePeriod),
+		StopTimeout: int64(p.Job.StopTimeout),
+		Limits:      limits(p.Job.Limits),
+		bin:         bin,
+		runtime:     r,
+		stop:        make(chan struct{}),
+		watchers:    newBroadcaster(),
+		summary:     newSummary(),
+	}
+
+	if j.Duration == 0 && j.stagesDuration() == 0 {
+		return nil, fmt.Errorf("job is missing a duration")
+	}
+
+	if j.Shared && *capacity > 0 && j.reservation() > *capacity {
+		return nil, fmt.Errorf("job needs %d users, more than this agent's capacity of %d", j.reservation(), *capacity)
+	}
+
+	// Secrets are read again when the job starts; reading them now
+	// is only to catch missing ones early
+	_, err = j.environment()
+	if err != nil {
+		return nil, err
+	}
+
+	j.thresholds, err = parseThresholds(j.Thresholds)
+	if err != nil {
+		return nil, err
+	}
+
+	// Feeders, like binaries, are loaded in the background; only
+	// their paths are checked now
+	if j.Feeder.Path != "" {
+		err = j.Feeder.validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Binaries with URLs are fetched in the background, and verified
+	// when their job starts, so that a slow download doesn't hold up
+	// whoever created the job. They must be signed, though, before
+	// they're fetched at all
+	if j.bin.URL == "" {
+		err = j.bin.verify()
+	} else {
+		err = j.bin.signed()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.NewV4()
+	if err != nil {
+		return
+	}
+
+	j.ID = id.String()
+
+	return
+}
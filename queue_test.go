@@ -0,0 +1,503 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-lo/agent/agent"
+	"github.com/go-lo/go-lo"
+	"google.golang.org/grpc"
+)
+
+func TestQueue_Create(t *testing.T) {
+	c := 100
+	capacity = &c
+
+	defer func() {
+		c = 0
+	}()
+
+	dir := "testdata"
+	feederDir = &dir
+
+	valid := &agent.Job{
+		Name:     "test",
+		Users:    10,
+		Duration: 1,
+		Binary:   "testdata/dummy-process",
+	}
+
+	for _, test := range []struct {
+		name        string
+		payload     *agent.Payload
+		expectError bool
+	}{
+		{"happy path", &agent.Payload{Version: "job:latest", Job: valid}, false},
+		{"missing job", &agent.Payload{Version: "job:latest"}, true},
+		{"missing name", &agent.Payload{Job: &agent.Job{Duration: 1, Container: "foo"}}, true},
+		{"name with a path", &agent.Payload{Job: &agent.Job{Name: "../../etc", Duration: 1, Container: "foo"}}, true},
+		{"name with a separator", &agent.Payload{Job: &agent.Job{Name: "tests/smoke", Duration: 1, Container: "foo"}}, true},
+		{"missing duration", &agent.Payload{Job: &agent.Job{Name: "test", Container: "foo"}}, true},
+		{"missing container", &agent.Payload{Job: &agent.Job{Name: "test", Duration: 1}}, true},
+		{"container runtime without a container", &agent.Payload{Job: &agent.Job{Name: "test", Duration: 1, Runtime: RuntimeContainer, Binary: "foo"}}, true},
+		{"local runtime without a binary", &agent.Payload{Job: &agent.Job{Name: "test", Duration: 1, Runtime: RuntimeLocal, Container: "foo"}}, true},
+		{"fake runtime", &agent.Payload{Job: &agent.Job{Name: "test", Duration: 1, Runtime: RuntimeFake}}, false},
+		{"binary url without a digest", &agent.Payload{Job: &agent.Job{Name: "test", Duration: 1, Binary: "https://example.com/schedule"}}, true},
+		{"shared, over capacity", &agent.Payload{Job: &agent.Job{Name: "test", Duration: 1, Binary: "foo", Users: 1000, Shared: true}}, true},
+		{"grpc transport", &agent.Payload{Job: &agent.Job{Name: "test", Duration: 1, Binary: "foo", Transport: TransportGRPC}}, false},
+		{"legacy grpc transport", &agent.Payload{Job: &agent.Job{Name: "test", Duration: 1, Binary: "foo", Transport: TransportGRPC, Legacy: true}}, true},
+		{"unknown transport", &agent.Payload{Job: &agent.Job{Name: "test", Duration: 1, Binary: "foo", Transport: "carrier-pigeon"}}, true},
+		{"scenarios", &agent.Payload{Job: &agent.Job{Name: "test", Duration: 1, Binary: "foo", Scenarios: []*agent.Scenario{{Name: "browse", Weight: 3}, {Name: "search", Method: "Server.Search"}}}}, false},
+		{"unnamed scenario", &agent.Payload{Job: &agent.Job{Name: "test", Duration: 1, Binary: "foo", Scenarios: []*agent.Scenario{{Method: "Server.Search"}}}}, true},
+		{"duplicate scenarios", &agent.Payload{Job: &agent.Job{Name: "test", Duration: 1, Binary: "foo", Scenarios: []*agent.Scenario{{Name: "browse"}, {Name: "browse"}}}}, true},
+		{"params", &agent.Payload{Job: &agent.Job{Name: "test", Duration: 1, Binary: "foo", Params: map[string]string{"region": "eu"}}}, false},
+		{"feeder", &agent.Payload{Job: &agent.Job{Name: "test", Duration: 1, Binary: "foo", Feeder: &agent.Feeder{Path: "feeder.csv", Strategy: agent.Feeder_UNIQUE}}}, false},
+		{"missing feeder", &agent.Payload{Job: &agent.Job{Name: "test", Duration: 1, Binary: "foo", Feeder: &agent.Feeder{Path: "nonsuch.csv"}}}, true},
+		{"feeder out of the feeder directory", &agent.Payload{Job: &agent.Job{Name: "test", Duration: 1, Binary: "foo", Feeder: &agent.Feeder{Path: "../testdata/feeder.csv"}}}, true},
+		{"file url feeder", &agent.Payload{Job: &agent.Job{Name: "test", Duration: 1, Binary: "foo", Feeder: &agent.Feeder{Path: "file:///etc/passwd"}}}, true},
+		{"unique feeder with a rate", &agent.Payload{Job: &agent.Job{Name: "test", Duration: 1, Binary: "foo", Rate: 10, Feeder: &agent.Feeder{Path: "feeder.csv", Strategy: agent.Feeder_UNIQUE}}}, true},
+		{"stop user feeder with a rate", &agent.Payload{Job: &agent.Job{Name: "test", Duration: 1, Binary: "foo", Rate: 10, Feeder: &agent.Feeder{Path: "feeder.csv", Exhausted: agent.Feeder_STOP_USER}}}, true},
+		{"stop job feeder with a rate", &agent.Payload{Job: &agent.Job{Name: "test", Duration: 1, Binary: "foo", Rate: 10, Feeder: &agent.Feeder{Path: "feeder.csv", Exhausted: agent.Feeder_STOP_JOB}}}, false},
+		{"thresholds", &agent.Payload{Job: &agent.Job{Name: "test", Duration: 1, Binary: "foo", Thresholds: []*agent.Threshold{{Expression: "p95(duration) < 300ms"}, {Expression: "error_rate < 1%", Abort: true}}}}, false},
+		{"invalid threshold", &agent.Payload{Job: &agent.Job{Name: "test", Duration: 1, Binary: "foo", Thresholds: []*agent.Threshold{{Expression: "p95(duration) < soon"}}}}, true},
+		{"sinks", &agent.Payload{Job: &agent.Job{Name: "test", Duration: 1, Binary: "foo", Sinks: []*agent.Sink{{Type: SinkCollector}, {Type: SinkFile}, {Type: SinkWebhook, Url: "https://example.com/results"}}}}, false},
+		{"unknown sink", &agent.Payload{Job: &agent.Job{Name: "test", Duration: 1, Binary: "foo", Sinks: []*agent.Sink{{Type: "carrier-pigeon"}}}}, true},
+		{"unknown runtime", &agent.Payload{Job: &agent.Job{Name: "test", Duration: 1, Runtime: "nonsuch", Container: "foo"}}, true},
+		{"stages instead of duration", &agent.Payload{Job: &agent.Job{Name: "test", Container: "foo", Stages: []*agent.Stage{{Users: 10, Duration: 10}}}}, false},
+		{"stages without duration", &agent.Payload{Job: &agent.Job{Name: "test", Container: "foo", Stages: []*agent.Stage{{Users: 10}}}}, true},
+		{"rate", &agent.Payload{Job: &agent.Job{Name: "test", Duration: 1, Container: "foo", Rate: 100}}, false},
+		{"negative rate", &agent.Payload{Job: &agent.Job{Name: "test", Duration: 1, Container: "foo", Rate: -1}}, true},
+		{"rate too low", &agent.Payload{Job: &agent.Job{Name: "test", Duration: 1, Container: "foo", Rate: 1e-12}}, true},
+		{"rate too high", &agent.Payload{Job: &agent.Job{Name: "test", Duration: 1, Container: "foo", Rate: 1e12}}, true},
+		{"rate and stages", &agent.Payload{Job: &agent.Job{Name: "test", Container: "foo", Rate: 100, Stages: []*agent.Stage{{Users: 10, Duration: 10}}}}, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			q := NewQueue(discardSink{})
+
+			r, err := q.Create(context.Background(), test.payload)
+			if err != nil {
+				t.Fatalf("unexpected error %+v", err)
+			}
+
+			if test.expectError != r.Error {
+				t.Errorf("expected error %v, received %v: %q", test.expectError, r.Error, r.Output)
+			}
+
+			if !test.expectError {
+				if r.Id == "" {
+					t.Errorf("expected job ID")
+				}
+
+				if _, ok := q.jobs[r.Id]; !ok {
+					t.Errorf("job %s was not enqueued", r.Id)
+				}
+			}
+		})
+	}
+}
+
+func TestQueue_Position(t *testing.T) {
+	q := NewQueue(discardSink{})
+	p := &agent.Payload{
+		Job: &agent.Job{
+			Name:     "test",
+			Duration: 1,
+			Binary:   "testdata/dummy-process",
+		},
+	}
+
+	for _, expect := range []string{"0", "1", "2"} {
+		r, _ := q.Create(context.Background(), p)
+		if expect != r.Output {
+			t.Errorf("expected position %q, received %q", expect, r.Output)
+		}
+	}
+
+	t.Run("running jobs are counted", func(t *testing.T) {
+		q.next()
+
+		r, _ := q.Create(context.Background(), p)
+		if "3" != r.Output {
+			t.Errorf("expected position %q, received %q", "3", r.Output)
+		}
+	})
+}
+
+func TestQueue_Status(t *testing.T) {
+	q := NewQueue(discardSink{})
+	r, _ := q.Create(context.Background(), &agent.Payload{
+		Job: &agent.Job{
+			Name:     "test",
+			Duration: 1,
+			Binary:   "testdata/dummy-process",
+		},
+	})
+
+	t.Run("known job", func(t *testing.T) {
+		js, err := q.Status(context.Background(), &agent.JobID{Id: r.Id})
+		if err != nil {
+			t.Fatalf("unexpected error %+v", err)
+		}
+
+		if agent.JobStatus_QUEUED != js.State {
+			t.Errorf("expected %s, received %s", agent.JobStatus_QUEUED, js.State)
+		}
+
+		if js.Queued == nil {
+			t.Errorf("expected queued timestamp")
+		}
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		_, err := q.Status(context.Background(), &agent.JobID{Id: "nonsuch"})
+		if err == nil {
+			t.Errorf("expected error")
+		}
+	})
+}
+
+func TestQueue_List(t *testing.T) {
+	q := NewQueue(discardSink{})
+
+	ids := make([]string, 3)
+	for i := range ids {
+		r, _ := q.Create(context.Background(), &agent.Payload{
+			Job: &agent.Job{
+				Name:     "test",
+				Duration: 1,
+				Binary:   "testdata/dummy-process",
+			},
+		})
+
+		ids[i] = r.Id
+	}
+
+	jl, err := q.List(context.Background(), &agent.ListRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	if len(ids) != len(jl.Jobs) {
+		t.Fatalf("expected %d jobs, received %d", len(ids), len(jl.Jobs))
+	}
+
+	for i, js := range jl.Jobs {
+		if ids[i] != js.Id {
+			t.Errorf("expected job %d to be %s, received %s", i, ids[i], js.Id)
+		}
+	}
+}
+
+func TestQueue_Cancel(t *testing.T) {
+	q := NewQueue(discardSink{})
+	p := &agent.Payload{
+		Job: &agent.Job{
+			Name:     "test",
+			Duration: 1,
+			Binary:   "testdata/dummy-process",
+		},
+	}
+
+	queued, _ := q.Create(context.Background(), p)
+	finished, _ := q.Create(context.Background(), p)
+	q.jobs[finished.Id].state = agent.JobStatus_COMPLETED
+
+	for _, test := range []struct {
+		name        string
+		id          string
+		expectError bool
+	}{
+		{"queued job", queued.Id, false},
+		{"finished job", finished.Id, true},
+		{"unknown job", "nonsuch", true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			r, err := q.Cancel(context.Background(), &agent.JobID{Id: test.id})
+			if err != nil {
+				t.Fatalf("unexpected error %+v", err)
+			}
+
+			if test.expectError != r.Error {
+				t.Errorf("expected error %v, received %v: %q", test.expectError, r.Error, r.Output)
+			}
+		})
+	}
+
+	t.Run("cancelled job is dequeued", func(t *testing.T) {
+		j := q.jobs[queued.Id]
+
+		if agent.JobStatus_CANCELLED != j.state {
+			t.Errorf("expected %s, received %s", agent.JobStatus_CANCELLED, j.state)
+		}
+
+		for _, p := range q.pending {
+			if p == j {
+				t.Errorf("cancelled job is still pending")
+			}
+		}
+	})
+}
+
+func TestQueue_Retain(t *testing.T) {
+	defer func(r int) {
+		retain = &r
+	}(*retain)
+
+	r := 2
+	retain = &r
+
+	q := NewQueue(discardSink{})
+	p := &agent.Payload{
+		Job: &agent.Job{
+			Name:     "test",
+			Duration: 1,
+			Binary:   "testdata/dummy-process",
+		},
+	}
+
+	ids := make([]string, 3)
+	for i := range ids {
+		resp, _ := q.Create(context.Background(), p)
+		ids[i] = resp.Id
+	}
+
+	j := q.next()
+	j.summary = newSummary()
+	j.summary.add(Output{Output: golo.Output{URL: "http://example.com", Method: "GET"}})
+	q.finish(j, nil)
+
+	t.Run("finished jobs are released", func(t *testing.T) {
+		if j.summary.total != nil {
+			t.Errorf("expected summary to be released")
+		}
+
+		js, err := q.Status(context.Background(), &agent.JobID{Id: j.ID})
+		if err != nil {
+			t.Fatalf("unexpected error %+v", err)
+		}
+
+		if js.Summary.GetTotal().GetCount() != 1 {
+			t.Errorf("expected summary of 1 output, received %d", js.Summary.GetTotal().GetCount())
+		}
+	})
+
+	for _, id := range ids[1:] {
+		q.Cancel(context.Background(), &agent.JobID{Id: id})
+	}
+
+	for _, test := range []struct {
+		name   string
+		id     string
+		expect bool
+	}{
+		{"oldest job", ids[0], false},
+		{"cancelled job", ids[1], true},
+		{"newest job", ids[2], true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, ok := q.jobs[test.id]
+			if test.expect != ok {
+				t.Errorf("expected %v, received %v", test.expect, ok)
+			}
+		})
+	}
+}
+
+type dummyWatchServer struct {
+	grpc.ServerStream
+
+	ctx     context.Context
+	results []*agent.Result
+}
+
+func (s *dummyWatchServer) Send(r *agent.Result) error {
+	s.results = append(s.results, r)
+
+	return nil
+}
+
+func (s *dummyWatchServer) Context() context.Context {
+	return s.ctx
+}
+
+func TestQueue_Watch(t *testing.T) {
+	q := NewQueue(discardSink{})
+	r, _ := q.Create(context.Background(), &agent.Payload{
+		Job: &agent.Job{
+			Name:     "test",
+			Duration: 1,
+			Binary:   "testdata/dummy-process",
+		},
+	})
+
+	j := q.jobs[r.Id]
+
+	for _, test := range []struct {
+		name        string
+		request     *agent.WatchRequest
+		expect      int
+		expectError bool
+	}{
+		{"every result", &agent.WatchRequest{Id: r.Id}, 10, false},
+		{"sampled results", &agent.WatchRequest{Id: r.Id, Sample: 0.5}, 5, false},
+		{"unknown job", &agent.WatchRequest{Id: "nonsuch"}, 0, true},
+		{"invalid sample", &agent.WatchRequest{Id: r.Id, Sample: 1.5}, 0, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			b := newBroadcaster()
+			j.watchers = b
+
+			subscribes := !test.expectError
+
+			s := &dummyWatchServer{ctx: context.Background()}
+
+			go func() {
+				// wait for the watcher to subscribe
+				for subscribes {
+					b.mutex.Lock()
+					subscribed := len(b.subscribers) > 0
+					b.mutex.Unlock()
+
+					if subscribed {
+						break
+					}
+				}
+
+				for i := 0; i < 10; i++ {
+					b.publish(Output{Output: golo.Output{URL: "http://example.com"}})
+				}
+
+				b.close()
+			}()
+
+			err := q.Watch(test.request, s)
+			if test.expectError && err == nil {
+				t.Errorf("expected error")
+			}
+
+			if !test.expectError && err != nil {
+				t.Errorf("unexpected error %+v", err)
+			}
+
+			if test.expect != len(s.results) {
+				t.Errorf("expected %d results, received %d", test.expect, len(s.results))
+			}
+		})
+	}
+}
+
+func TestJob_Reservation(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		job    *Job
+		expect int
+	}{
+		{"users", &Job{Users: 10}, 10},
+		{"default users", &Job{}, DefaultUserCount},
+		{"stages", &Job{Stages: []Stage{{Users: 10}, {Users: 50}, {Users: 0}}}, 50},
+		{"rate", &Job{Rate: 100, MaxInFlight: 20}, 20},
+		{"rate, default max in flight", &Job{Rate: 100}, DefaultMaxInFlight},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if received := test.job.reservation(); test.expect != received {
+				t.Errorf("expected %d, received %d", test.expect, received)
+			}
+		})
+	}
+}
+
+func TestQueue_Admits(t *testing.T) {
+	c := 100
+	capacity = &c
+
+	defer func() {
+		c = 0
+	}()
+
+	for _, test := range []struct {
+		name    string
+		running []*Job
+		job     *Job
+		expect  bool
+	}{
+		{"nothing running", nil, &Job{Users: 10}, true},
+		{"nothing running, over capacity", nil, &Job{Users: 1000, Shared: true}, true},
+		{"exclusive job, behind a shared job", []*Job{{Users: 10, Shared: true}}, &Job{Users: 10}, false},
+		{"shared job, behind an exclusive job", []*Job{{Users: 10}}, &Job{Users: 10, Shared: true}, false},
+		{"shared job, with room", []*Job{{Users: 50, Shared: true}, {Users: 30, Shared: true}}, &Job{Users: 20, Shared: true}, true},
+		{"shared job, without room", []*Job{{Users: 50, Shared: true}, {Users: 30, Shared: true}}, &Job{Users: 21, Shared: true}, false},
+		{"legacy job, behind a shared job", []*Job{{Users: 10, Shared: true}}, &Job{Users: 10, Shared: true, Legacy: true}, true},
+		{"legacy job, behind a legacy job", []*Job{{Users: 10, Shared: true, Legacy: true}}, &Job{Users: 10, Shared: true, Legacy: true}, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			q := NewQueue(discardSink{})
+			for _, j := range test.running {
+				q.running[j] = true
+				q.reserved += j.reservation()
+				q.exclusive = !j.Shared
+				q.legacy = q.legacy || j.Legacy
+			}
+
+			if received := q.admits(test.job); test.expect != received {
+				t.Errorf("expected %v, received %v", test.expect, received)
+			}
+		})
+	}
+}
+
+func TestQueue_Next(t *testing.T) {
+	c := 100
+	capacity = &c
+
+	defer func() {
+		c = 0
+	}()
+
+	q := NewQueue(discardSink{})
+
+	first := &Job{ID: "first", Users: 80, Shared: true, watchers: newBroadcaster()}
+	second := &Job{ID: "second", Users: 30, Shared: true, watchers: newBroadcaster()}
+	third := &Job{ID: "third", Users: 10, Shared: true, watchers: newBroadcaster()}
+
+	for _, j := range []*Job{first, second, third} {
+		q.enqueue(j)
+	}
+
+	if j := q.next(); first != j {
+		t.Fatalf("expected %s, received %s", first.ID, j.ID)
+	}
+
+	next := make(chan *Job)
+	go func() {
+		next <- q.next()
+	}()
+
+	// third would fit alongside first, but mustn't overtake second
+	select {
+	case j := <-next:
+		t.Fatalf("expected second to wait for room, received %s", j.ID)
+
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	q.finish(first, nil)
+
+	select {
+	case j := <-next:
+		if second != j {
+			t.Errorf("expected %s, received %s", second.ID, j.ID)
+		}
+
+	case <-time.After(time.Second):
+		t.Fatalf("expected second to be admitted once first finished")
+	}
+
+	if j := q.next(); third != j {
+		t.Errorf("expected %s, received %s", third.ID, j.ID)
+	}
+
+	if 40 != q.reserved {
+		t.Errorf("expected %d users reserved, received %d", 40, q.reserved)
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/go-lo/go-lo"
+)
+
+const (
+	// RPCNetworkEnv and RPCAddrEnv are the environment variables which
+	// tell a schedule the network ("tcp" or "unix") and address to serve
+	// RPC on
+	RPCNetworkEnv = "GOLO_RPC_NETWORK"
+	RPCAddrEnv    = "GOLO_RPC_ADDR"
+)
+
+var (
+	rpcNetwork = flag.String("rpc-network", "tcp", "network schedules serve RPC on; tcp, for a free port on localhost, or unix, for a socket in -sockets")
+	socketDir  = flag.String("sockets", filepath.Join(os.TempDir(), "go-lo"), "directory to create schedule RPC sockets in, when -rpc-network is unix")
+
+	// reserved are the tcp addresses handed out to jobs which haven't
+	// finished, along with the listener holding each one until its
+	// schedule is about to start. Nothing else can take an address while
+	// it's held, and the agent won't hand it out again until its job is
+	// done with it
+	reserved      = make(map[string]net.Listener)
+	reservedMutex sync.Mutex
+
+	// legacyRPCAddr is go-lo's fixed RPC address, which the schedules
+	// of Legacy jobs listen on instead of the address they're given
+	legacyRPCAddr = rpcAddr{network: "tcp", address: golo.RPCAddr}
+)
+
+// rpcAddr is where a schedule serves RPC. Each job gets its own, so
+// that jobs can't collide with each other, or with anything else on
+// the agent
+type rpcAddr struct {
+	network string
+	address string
+}
+
+// newRPCAddr returns an unused address on rpcNetwork for the job
+// with the ID id
+func newRPCAddr(id string) (a rpcAddr, err error) {
+	switch *rpcNetwork {
+	case "tcp":
+		reservedMutex.Lock()
+		defer reservedMutex.Unlock()
+
+		// Listening on port 0 has the kernel pick a free port. Ports
+		// which are free only because their schedule hasn't started
+		// yet are held on to, so the kernel picks another
+		var l net.Listener
+
+		for {
+			l, err = net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				return
+			}
+
+			_, ok := reserved[l.Addr().String()]
+			if !ok {
+				break
+			}
+
+			defer l.Close()
+		}
+
+		a = rpcAddr{network: "tcp", address: l.Addr().String()}
+		reserved[a.address] = l
+
+	case "unix":
+		// The socket directory is only accessible to the agent (and its
+		// schedules) so nothing else can listen on, or dial, its sockets.
+		// MkdirAll leaves an existing directory's permissions be, so
+		// they're set again
+		err = os.MkdirAll(*socketDir, 0700)
+		if err != nil {
+			return
+		}
+
+		err = os.Chmod(*socketDir, 0700)
+		if err != nil {
+			return
+		}
+
+		a = rpcAddr{network: "unix", address: filepath.Join(*socketDir, id+".sock")}
+
+	default:
+		err = fmt.Errorf("unknown rpc network %q", *rpcNetwork)
+	}
+
+	return
+}
+
+// release gives up the listener holding a tcp address, so that the
+// schedule about to start can listen on it
+func (a rpcAddr) release() {
+	if a.network != "tcp" {
+		return
+	}
+
+	reservedMutex.Lock()
+	defer reservedMutex.Unlock()
+
+	l := reserved[a.address]
+	if l != nil {
+		l.Close()
+		reserved[a.address] = nil
+	}
+}
+
+// env returns the environment variables a schedule serving RPC
+// on a reads its address from
+func (a rpcAddr) env() []string {
+	return []string{
+		RPCNetworkEnv + "=" + a.network,
+		RPCAddrEnv + "=" + a.address,
+	}
+}
+
+// remove removes a unix socket, or frees up a tcp address to be handed
+// out again, once its schedule is done with it
+func (a rpcAddr) remove() {
+	switch a.network {
+	case "tcp":
+		a.release()
+
+		reservedMutex.Lock()
+		delete(reserved, a.address)
+		reservedMutex.Unlock()
+
+	case "unix":
+		os.Remove(a.address)
+	}
+}
+
+func (a rpcAddr) String() string {
+	return a.network + ":" + a.address
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewRPCAddr(t *testing.T) {
+	sockets := filepath.Join(td, "sockets")
+	socketDir = &sockets
+
+	// An existing directory is made private, too
+	err := os.MkdirAll(sockets, 0755)
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	defaultNetwork := *rpcNetwork
+	defer func() {
+		rpcNetwork = &defaultNetwork
+	}()
+
+	for _, test := range []struct {
+		network     string
+		expectError bool
+	}{
+		{"tcp", false},
+		{"unix", false},
+		{"udp", true},
+	} {
+		t.Run(test.network, func(t *testing.T) {
+			network := test.network
+			rpcNetwork = &network
+
+			a, err := newRPCAddr("test")
+			if test.expectError && err == nil {
+				t.Errorf("expected error")
+			}
+
+			if !test.expectError && err != nil {
+				t.Errorf("unexpected error %+v", err)
+			}
+
+			if test.expectError {
+				return
+			}
+
+			if test.network != a.network {
+				t.Errorf("expected %q, received %q", test.network, a.network)
+			}
+
+			if test.network == "unix" && filepath.Join(sockets, "test.sock") != a.address {
+				t.Errorf("expected %q, received %q", filepath.Join(sockets, "test.sock"), a.address)
+			}
+
+			// Whatever address we're given should be free to listen on,
+			// once released
+			a.release()
+
+			l, err := net.Listen(a.network, a.address)
+			if err != nil {
+				t.Fatalf("unexpected error %+v", err)
+			}
+
+			l.Close()
+			a.remove()
+
+			expect := []string{RPCNetworkEnv + "=" + a.network, RPCAddrEnv + "=" + a.address}
+			if strings.Join(expect, ",") != strings.Join(a.env(), ",") {
+				t.Errorf("expected %q, received %q", expect, a.env())
+			}
+		})
+	}
+
+	fi, err := os.Stat(sockets)
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	if fi.Mode().Perm() != 0700 {
+		t.Errorf("expected socket directory to be private, received %s", fi.Mode())
+	}
+}
+
+func TestNewRPCAddr_Unique(t *testing.T) {
+	a, err := newRPCAddr("a")
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	defer a.remove()
+
+	// Even once its schedule has taken it, an address isn't handed
+	// out again until its job is done with it
+	a.release()
+
+	l, err := net.Listen(a.network, a.address)
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	l.Close()
+
+	b, err := newRPCAddr("b")
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	defer b.remove()
+
+	if a == b {
+		t.Errorf("expected jobs to be given different addresses, both received %s", a)
+	}
+
+	// Whereas one which hasn't been released is held onto
+	_, err = net.Listen(b.network, b.address)
+	if err == nil {
+		t.Errorf("expected %s to be held", b)
+	}
+}
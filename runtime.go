@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"syscall"
+)
+
+const (
+	// RuntimeContainer runs a schedule from a container image,
+	// via a container runtime CLI
+	RuntimeContainer = "container"
+
+	// RuntimeLocal runs a schedule binary already on the agent
+	RuntimeLocal = "local"
+
+	// RuntimeFake runs a pretend schedule inside the agent
+	RuntimeFake = "fake"
+)
+
+// runtime starts, stops and waits on a job's schedule, and provides
+// its output and the address its RPC server listens on
+type runtime interface {
+	// start starts the schedule for j
+	start(j *Job) error
+
+	// stop asks the schedule to exit, or, when force is set,
+	// kills it
+	stop(force bool) error
+
+	// wait blocks until the schedule has exited
+	wait() (exit, error)
+
+	// cleanup kills and removes anything the schedule leaves
+	// behind once it has exited
+	cleanup()
+
+	stdout() io.Reader
+	stderr() io.Reader
+
+	// address is where the schedule serves RPC
+	address() rpcAddr
+}
+
+// newRuntime returns the runtime called name
+func newRuntime(name string) (r runtime, err error) {
+	switch name {
+	case RuntimeContainer:
+		r = new(containerRuntime)
+	case RuntimeLocal:
+		r = new(localRuntime)
+	case RuntimeFake:
+		r = new(fakeRuntime)
+	default:
+		err = fmt.Errorf("unknown runtime %q", name)
+	}
+
+	return
+}
+
+// exit is how a schedule exited
+type exit struct {
+	code      int
+	signal    syscall.Signal
+	oomKilled bool
+}
+
+func (e exit) success() bool {
+	return e.code == 0 && e.signal == 0
+}
+
+func (e exit) String() string {
+	if e.signal != 0 {
+		return fmt.Sprintf("signal: %s", e.signal)
+	}
+
+	return fmt.Sprintf("exit status %d", e.code)
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+var (
+	containerCLI = flag.String("container-runtime", "docker", "CLI used to run schedule containers; anything which takes docker's arguments, such as podman, works")
+)
+
+// containerRuntime runs a schedule from a container image by shelling
+// out to a container runtime CLI. Containers share the agent's network,
+// so that schedules can serve RPC on the address the agent gives them
+type containerRuntime struct {
+	name    string
+	addr    rpcAddr
+	envFile string
+	cmd     *exec.Cmd
+	out     io.Reader
+	err     io.Reader
+}
+
+func (r *containerRuntime) start(j *Job) (err error) {
+	r.name = "go-lo-" + j.ID
+
+	args := []string{"run", "--name", r.name, "--network", "host"}
+
+	if j.Limits.CPU > 0 {
+		args = append(args, "--cpus", strconv.FormatFloat(j.Limits.CPU, 'f', -1, 64))
+	}
+
+	if j.Limits.Memory > 0 {
+		args = append(args, "--memory", strconv.FormatInt(j.Limits.Memory, 10))
+	}
+
+	if j.Limits.Pids > 0 {
+		args = append(args, "--pids-limit", strconv.FormatInt(j.Limits.Pids, 10))
+	}
+
+	if j.Workdir != "" {
+		args = append(args, "--workdir", j.Workdir)
+	}
+
+	// Env vars are passed in a file only the agent can read, which keeps
+	// secrets out of the runtime CLI's arguments (and so out of ps), and
+	// keeps the job's env out of the CLI's own environment. Containers
+	// don't inherit anything from the agent
+	env, err := j.environment()
+	if err != nil {
+		return
+	}
+
+	env = append(env, j.rpc.env()...)
+
+	// Unix sockets are shared with the container by mounting the
+	// directory they're created in
+	if j.rpc.network == "unix" {
+		dir := filepath.Dir(j.rpc.address)
+		args = append(args, "--volume", dir+":"+dir)
+	}
+
+	r.envFile, err = writeEnvFile(env)
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			os.Remove(r.envFile)
+		}
+	}()
+
+	args = append(args, "--env-file", r.envFile, j.Container)
+
+	r.cmd = exec.Command(*containerCLI, append(args, j.Args...)...)
+
+	r.err, err = r.cmd.StderrPipe()
+	if err != nil {
+		return
+	}
+
+	r.out, err = r.cmd.StdoutPipe()
+	if err != nil {
+		return
+	}
+
+	r.addr = j.rpc
+
+	err = r.cmd.Start()
+
+	return
+}
+
+// writeEnvFile writes env to a file, of a KEY=value pair per line, which
+// only the agent can read, for the runtime CLI's --env-file
+func writeEnvFile(env []string) (path string, err error) {
+	f, err := ioutil.TempFile("", "go-lo-env-")
+	if err != nil {
+		return
+	}
+
+	defer f.Close()
+
+	for _, e := range env {
+		// Each line is a variable, so a newline would start another
+		if strings.ContainsAny(e, "\r\n") {
+			err = fmt.Errorf("env var %s can't contain a newline", e[:strings.Index(e, "=")])
+
+			break
+		}
+
+		_, err = fmt.Fprintln(f, e)
+		if err != nil {
+			break
+		}
+	}
+
+	if err != nil {
+		os.Remove(f.Name())
+
+		return "", err
+	}
+
+	return f.Name(), nil
+}
+
+func (r *containerRuntime) stop(force bool) error {
+	sig := "TERM"
+	if force {
+		sig = "KILL"
+	}
+
+	return r.run("kill", "--signal", sig, r.name)
+}
+
+// wait waits on the runtime CLI, which exits with the container's
+// exit status once the container exits
+func (r *containerRuntime) wait() (e exit, err error) {
+	state, err := r.cmd.Process.Wait()
+	if err != nil {
+		return
+	}
+
+	e.code = state.ExitCode()
+	if ws, ok := state.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		e.signal = ws.Signal()
+	}
+
+	out, err := exec.Command(*containerCLI, "inspect", "--format", "{{.State.OOMKilled}}", r.name).Output()
+	if err == nil {
+		e.oomKilled = string(bytes.TrimSpace(out)) == "true"
+	}
+
+	return e, nil
+}
+
+// cleanup removes the container, killing it first if it's somehow
+// still running, and its env file
+func (r *containerRuntime) cleanup() {
+	r.run("rm", "--force", r.name)
+	os.Remove(r.envFile)
+}
+
+func (r *containerRuntime) stdout() io.Reader {
+	return r.out
+}
+
+func (r *containerRuntime) stderr() io.Reader {
+	return r.err
+}
+
+func (r *containerRuntime) address() rpcAddr {
+	return r.addr
+}
+
+// run runs the runtime CLI with args, returning anything it
+// writes to stderr as an error when it fails
+func (r *containerRuntime) run(args ...string) (err error) {
+	stderr := new(bytes.Buffer)
+
+	cmd := exec.Command(*containerCLI, args...)
+	cmd.Stderr = stderr
+
+	err = cmd.Run()
+	if err != nil {
+		return fmt.Errorf("%s %s: %s", *containerCLI, args[0], bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	return
+}
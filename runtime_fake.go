@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/rpc"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/go-lo/agent/agent"
+	"github.com/go-lo/go-lo"
+	"google.golang.org/grpc"
+)
+
+// fakeRuntime runs a pretend schedule inside the agent, which answers
+// every call with a made up golo.Output. It stands in for a real schedule
+// when testing agents, and the tools which drive them
+type fakeRuntime struct {
+	addr     rpcAddr
+	listener net.Listener
+	outR     *io.PipeReader
+	outW     *io.PipeWriter
+	errR     *io.PipeReader
+	errW     *io.PipeWriter
+	shutdown func()
+	exited   chan struct{}
+	once     sync.Once
+	killed   bool
+}
+
+func (r *fakeRuntime) start(j *Job) (err error) {
+	r.addr = j.rpc
+
+	r.listener, err = net.Listen(r.addr.network, r.addr.address)
+	if err != nil {
+		return
+	}
+
+	r.outR, r.outW = io.Pipe()
+	r.errR, r.errW = io.Pipe()
+	r.exited = make(chan struct{})
+
+	s := &fakeSchedule{w: r.outW}
+
+	if j.Transport == TransportGRPC {
+		server := grpc.NewServer()
+		agent.RegisterScheduleServer(server, fakeGRPCSchedule{s})
+
+		r.shutdown = server.Stop
+		go server.Serve(r.listener)
+
+		return
+	}
+
+	server := rpc.NewServer()
+
+	err = server.RegisterName("Server", s)
+	if err != nil {
+		return
+	}
+
+	r.shutdown = func() {
+		r.listener.Close()
+	}
+
+	go server.Accept(r.listener)
+
+	return
+}
+
+func (r *fakeRuntime) stop(force bool) error {
+	r.once.Do(func() {
+		r.killed = force
+
+		r.shutdown()
+		r.outW.Close()
+		r.errW.Close()
+
+		close(r.exited)
+	})
+
+	return nil
+}
+
+func (r *fakeRuntime) wait() (e exit, err error) {
+	<-r.exited
+
+	if r.killed {
+		e.signal = syscall.SIGKILL
+	}
+
+	return
+}
+
+func (r *fakeRuntime) cleanup() {}
+
+func (r *fakeRuntime) stdout() io.Reader {
+	return r.outR
+}
+
+func (r *fakeRuntime) stderr() io.Reader {
+	return r.errR
+}
+
+func (r *fakeRuntime) address() rpcAddr {
+	return r.addr
+}
+
+// fakeSchedule is the RPC server of a fakeRuntime, for jobs
+// using TransportRPC
+type fakeSchedule struct {
+	mutex    sync.Mutex
+	w        io.Writer
+	sequence int
+}
+
+// Run writes an Output for a successful, instant, request to stdout,
+// as a schedule would
+func (s *fakeSchedule) Run(c *Call, _ *golo.NullArg) error {
+	return s.run(c.Scenario)
+}
+
+// run writes an Output tagged with the scenario it was called for
+func (s *fakeSchedule) run(scenario string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.sequence++
+
+	return json.NewEncoder(s.w).Encode(Output{
+		Output: golo.Output{
+			SequenceID: strconv.Itoa(s.sequence),
+			URL:        "fake://schedule",
+			Method:     "GET",
+			Status:     200,
+			Timestamp:  time.Now(),
+		},
+		Scenario: scenario,
+	})
+}
+
+// fakeGRPCSchedule serves a fakeSchedule over gRPC, for jobs
+// using TransportGRPC
+type fakeGRPCSchedule struct {
+	s *fakeSchedule
+}
+
+func (g fakeGRPCSchedule) Ready(context.Context, *agent.ReadyRequest) (*agent.ReadyResponse, error) {
+	return &agent.ReadyResponse{Ready: true}, nil
+}
+
+func (g fakeGRPCSchedule) Run(_ context.Context, r *agent.RunRequest) (*agent.RunResponse, error) {
+	return &agent.RunResponse{}, g.s.run(r.Scenario)
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// localRuntime runs a schedule binary on the agent, in its own
+// process group and, where the job has limits, its own cgroup
+type localRuntime struct {
+	addr    rpcAddr
+	path    string
+	dir     string
+	process *os.Process
+	cgroup  *cgroup
+	out     io.Reader
+	err     io.Reader
+	killed  bool
+}
+
+func (r *localRuntime) start(j *Job) (err error) {
+	// Fetched binaries can be evicted from the cache while their
+	// job is queued, in which case they're fetched again
+	err = j.bin.fetch()
+	if err != nil {
+		return
+	}
+
+	// Agents with trusted keys run a private copy of the binary,
+	// verified as it's made, so that it can't be swapped out between
+	// being verified and being run
+	r.path = j.bin.Path
+	if len(trustedKeys) > 0 {
+		r.dir, err = ioutil.TempDir("", "go-lo-schedule-")
+		if err != nil {
+			return
+		}
+
+		// cleanup is only called for schedules which started
+		defer func() {
+			if err != nil {
+				os.RemoveAll(r.dir)
+			}
+		}()
+
+		r.path, err = j.bin.install(r.dir)
+		if err != nil {
+			return
+		}
+	}
+
+	env, err := j.environment()
+	if err != nil {
+		return
+	}
+
+	// Limits are best effort: where cgroups aren't available, or
+	// writable, schedules run without them
+	if j.Limits.set() {
+		r.cgroup, err = newCgroup(fmt.Sprintf("schedule-%s", j.ID), j.Limits)
+		if err != nil {
+			log.Printf("%s: running without limits: %+v", j.Name, err)
+
+			r.cgroup = nil
+		}
+	}
+
+	cmd, err := r.command(j, env)
+	if err != nil {
+		return
+	}
+
+	err = cmd.Start()
+	if err != nil && r.cgroup != nil {
+		// Kernels older than 5.7 can't start processes in a cgroup
+		log.Printf("%s: running without limits: %+v", j.Name, err)
+
+		r.cgroup.remove()
+		r.cgroup = nil
+
+		cmd, err = r.command(j, env)
+		if err != nil {
+			return
+		}
+
+		err = cmd.Start()
+	}
+
+	if err != nil {
+		return
+	}
+
+	r.process = cmd.Process
+	r.addr = j.rpc
+
+	// Schedules built with toolchains which can't start them in their
+	// cgroup are moved into it as soon as they've started instead
+	if r.cgroup != nil && !attachedAtStart {
+		err = r.cgroup.add(r.process.Pid)
+		if err != nil {
+			log.Printf("%s: running without limits: %+v", j.Name, err)
+
+			r.cgroup.remove()
+			r.cgroup = nil
+			err = nil
+		}
+	}
+
+	return
+}
+
+// command returns the command which runs j's schedule, with env, and
+// in the runtime's cgroup if it has one. Where the toolchain allows,
+// schedules start in their cgroup, rather than being moved into it once
+// running, so that nothing they start in the meantime escapes its limits
+func (r *localRuntime) command(j *Job, env []string) (cmd *exec.Cmd, err error) {
+	cmd = exec.Command(r.path, j.Args...)
+	cmd.Env = append(append(inherited(), env...), j.rpc.env()...)
+	cmd.Dir = j.Workdir
+
+	// Run the schedule in its own process group, so that it and anything
+	// it starts can be signalled, and cleaned up, together
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid: true,
+	}
+
+	err = r.cgroup.attach(cmd.SysProcAttr)
+	if err != nil {
+		return
+	}
+
+	r.err, err = cmd.StderrPipe()
+	if err != nil {
+		return
+	}
+
+	r.out, err = cmd.StdoutPipe()
+
+	return
+}
+
+func (r *localRuntime) stop(force bool) error {
+	if force {
+		return r.signal(syscall.SIGKILL)
+	}
+
+	return r.signal(syscall.SIGTERM)
+}
+
+// wait waits on the process, rather than polling it, so that a crashed
+// schedule is noticed straight away, and can't be confused with whatever
+// reuses its PID.
+//
+// Anything left in the schedule's process group is killed before the
+// schedule is reaped, while its PID, and so the group's ID, can't have
+// been reused
+func (r *localRuntime) wait() (e exit, err error) {
+	if waitExited(r.process.Pid) == nil {
+		err = r.signal(syscall.SIGKILL)
+		if err != nil {
+			log.Print(err)
+		}
+
+		r.killed = true
+	}
+
+	state, err := r.process.Wait()
+	if err != nil {
+		return
+	}
+
+	e.code = state.ExitCode()
+	if ws, ok := state.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		e.signal = ws.Signal()
+	}
+
+	e.oomKilled = r.cgroup.oomKilled()
+
+	return
+}
+
+// cleanup kills anything left in the schedule's cgroup, reaps what's
+// left of its process group, and removes the schedule's cgroup, and
+// private copy of its binary, if it has them. Where wait couldn't kill
+// the process group before reaping the schedule, it's killed now
+func (r *localRuntime) cleanup() {
+	if !r.killed {
+		err := r.signal(syscall.SIGKILL)
+		if err != nil {
+			log.Print(err)
+		}
+	}
+
+	err := r.cgroup.kill()
+	if err != nil {
+		log.Print(err)
+	}
+
+	r.reap()
+	r.cgroup.remove()
+
+	if r.dir != "" {
+		os.RemoveAll(r.dir)
+	}
+}
+
+func (r *localRuntime) stdout() io.Reader {
+	return r.out
+}
+
+func (r *localRuntime) stderr() io.Reader {
+	return r.err
+}
+
+func (r *localRuntime) address() rpcAddr {
+	return r.addr
+}
+
+// signal sends sig to every process in the schedule's process group
+func (r *localRuntime) signal(sig syscall.Signal) (err error) {
+	err = syscall.Kill(-r.process.Pid, sig)
+	if err == syscall.ESRCH {
+		return nil
+	}
+
+	return
+}
+
+// reap waits on anything left in the schedule's process group, so
+// that helpers orphaned by the schedule don't linger as zombies. This
+// relies on the agent having become their parent; see subreaper
+func (r *localRuntime) reap() {
+	for {
+		_, err := syscall.Wait4(-r.process.Pid, nil, 0, nil)
+		if err == syscall.EINTR {
+			continue
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,32 @@
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// pPID is waitid's idtype for waiting on a single process
+const pPID = 1
+
+// waitExited blocks until the process pid has exited, but leaves it
+// unreaped, so that its PID, and the ID of any process group it leads,
+// can't be reused until it is
+func waitExited(pid int) error {
+	// siginfo_t, which is filled in but never read
+	var info [128]byte
+
+	for {
+		_, _, errno := syscall.Syscall6(syscall.SYS_WAITID, pPID, uintptr(pid), uintptr(unsafe.Pointer(&info)), syscall.WEXITED|syscall.WNOWAIT, 0, 0)
+		switch errno {
+		case 0:
+			return nil
+		case syscall.EINTR:
+			continue
+		default:
+			return errno
+		}
+	}
+}
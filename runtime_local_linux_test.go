@@ -0,0 +1,86 @@
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestLocalRuntime_Wait(t *testing.T) {
+	// The schedule starts something which outlives it, in its process
+	// group, and exits straight away
+	cmd := exec.Command("sh", "-c", "sleep 30 & echo $!")
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid: true,
+	}
+
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	line, err := bufio.NewReader(out).ReadString('\n')
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	child, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	r := &localRuntime{
+		process: cmd.Process,
+	}
+
+	e, err := r.wait()
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	if !e.success() {
+		t.Errorf("expected schedule to succeed, received %s", e)
+	}
+
+	if !r.killed {
+		t.Errorf("expected process group to be killed before the schedule was reaped")
+	}
+
+	// Once killed, the child is reaped by whatever inherited it, or
+	// lingers as a zombie; either way, it isn't running
+	deadline := time.Now().Add(5 * time.Second)
+	for running(child) {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d to be killed", child)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// running returns whether pid is a process which hasn't exited
+func running(pid int) bool {
+	b, err := ioutil.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return false
+	}
+
+	// The state follows the command name, in parentheses
+	stat := string(b)
+	fields := strings.Fields(stat[strings.LastIndex(stat, ")")+1:])
+
+	return len(fields) > 0 && fields[0] != "Z"
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+// +build !linux
+
+package main
+
+import (
+	"fmt"
+)
+
+// waitExited can't wait for a process without reaping it on this
+// platform, so the rest of a schedule's process group is killed once
+// the schedule itself has been reaped
+func waitExited(int) error {
+	return fmt.Errorf("waiting without reaping is only supported on linux")
+}
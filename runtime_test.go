@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff"
+)
+
+func TestNewRuntime(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		expectError bool
+	}{
+		{RuntimeContainer, false},
+		{RuntimeLocal, false},
+		{RuntimeFake, false},
+		{"", true},
+		{"nonsuch", true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := newRuntime(test.name)
+			if test.expectError && err == nil {
+				t.Errorf("expected error")
+			}
+
+			if !test.expectError && err != nil {
+				t.Errorf("unexpected error %+v", err)
+			}
+		})
+	}
+}
+
+func TestFakeRuntime(t *testing.T) {
+	expoBackoff = backoff.NewExponentialBackOff()
+	expoBackoff.MaxElapsedTime = time.Second
+
+	logDir = &td
+	RPCCommand = "Server.Run"
+
+	for _, transport := range []string{TransportRPC, TransportGRPC} {
+		t.Run(transport, func(t *testing.T) {
+			outputs := make(chan Output)
+			received := make(chan int)
+
+			go func() {
+				var i int
+				for range outputs {
+					i++
+				}
+
+				received <- i
+			}()
+
+			j := Job{Name: "fake", Duration: 1, Pacing: 100, Users: 2, Transport: transport, runtime: new(fakeRuntime)}
+
+			err := j.Start(chanSink(outputs))
+			if err != nil {
+				t.Fatalf("unexpected error %+v", err)
+			}
+
+			close(outputs)
+
+			i := <-received
+			if i == 0 {
+				t.Errorf("expected outputs")
+			}
+
+			if j.summary.total.Count != int64(i) {
+				t.Errorf("expected summary of %d outputs, received %d", i, j.summary.total.Count)
+			}
+
+			if !j.exit.success() {
+				t.Errorf("expected schedule to exit cleanly, received %s", j.exit)
+			}
+		})
+	}
+}
+
+func TestContainerRuntime(t *testing.T) {
+	dir, err := ioutil.TempDir(td, "container-runtime")
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	// A stand in runtime CLI, which records what it's asked to do
+	calls := filepath.Join(dir, "calls")
+	cli := filepath.Join(dir, "docker")
+
+	err = ioutil.WriteFile(cli, []byte(fmt.Sprintf("#!/bin/sh\necho \"$@\" >> %s\n", calls)), 0755)
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	containerCLI = &cli
+	defer func() {
+		docker := "docker"
+		containerCLI = &docker
+	}()
+
+	r := new(containerRuntime)
+	j := &Job{
+		ID:        "abc",
+		Container: "schedule:latest",
+		Limits:    Limits{CPU: 1.5, Memory: 1024},
+		Args:      []string{"-v"},
+		Env:       map[string]string{"TARGET": "example.com"},
+		Workdir:   "/data",
+		rpc:       rpcAddr{network: "unix", address: "/run/go-lo/1.sock"},
+	}
+
+	err = r.start(j)
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	_, err = r.wait()
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	err = r.stop(true)
+	if err != nil {
+		t.Errorf("unexpected error %+v", err)
+	}
+
+	fi, err := os.Stat(r.envFile)
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	if fi.Mode().Perm() != 0600 {
+		t.Errorf("expected env file to be private, received %s", fi.Mode())
+	}
+
+	env, _ := ioutil.ReadFile(r.envFile)
+
+	expectEnv := "TARGET=example.com\nGOLO_RPC_NETWORK=unix\nGOLO_RPC_ADDR=/run/go-lo/1.sock\n"
+	if expectEnv != string(env) {
+		t.Errorf("expected %q, received %q", expectEnv, env)
+	}
+
+	r.cleanup()
+
+	_, err = os.Stat(r.envFile)
+	if !os.IsNotExist(err) {
+		t.Errorf("expected env file to be removed")
+	}
+
+	b, err := ioutil.ReadFile(calls)
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	expect := []string{
+		"run --name go-lo-abc --network host --cpus 1.5 --memory 1024 --workdir /data --volume /run/go-lo:/run/go-lo --env-file " + r.envFile + " schedule:latest -v",
+		"inspect --format {{.State.OOMKilled}} go-lo-abc",
+		"kill --signal KILL go-lo-abc",
+		"rm --force go-lo-abc",
+	}
+
+	received := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if strings.Join(expect, "\n") != strings.Join(received, "\n") {
+		t.Errorf("expected %q, received %q", expect, received)
+	}
+}
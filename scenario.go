@@ -0,0 +1,74 @@
+package main
+
+import (
+	"math/rand"
+
+	"github.com/go-lo/go-lo"
+)
+
+// Scenario is one of the user journeys a job's schedule can run, such
+// as browsing or checking out. Each call a user makes runs a scenario
+// picked at random, in proportion to its Weight, by calling Method on
+// the schedule. An unset Method is RPCCommand, and an unset Weight is 1
+type Scenario struct {
+	Name   string `json:"name"`
+	Method string `json:"method"`
+	Weight int    `json:"weight"`
+}
+
+func (s Scenario) method() string {
+	if s.Method == "" {
+		return RPCCommand
+	}
+
+	return s.Method
+}
+
+func (s Scenario) weight() int {
+	if s.Weight == 0 {
+		return 1
+	}
+
+	return s.Weight
+}
+
+// Output is a golo.Output from a schedule, tagged with the name of the
+// scenario which made it
+type Output struct {
+	golo.Output
+
+	Scenario string `json:"scenario,omitempty"`
+}
+
+// scenario picks the scenario for a call. Jobs without scenarios
+// always run the same, unnamed, one
+func (j *Job) scenario() Scenario {
+	if len(j.Scenarios) == 0 {
+		return Scenario{}
+	}
+
+	var total int
+	for _, s := range j.Scenarios {
+		total += s.weight()
+	}
+
+	n := rand.Intn(total)
+	for _, s := range j.Scenarios {
+		n -= s.weight()
+		if n < 0 {
+			return s
+		}
+	}
+
+	return j.Scenarios[len(j.Scenarios)-1]
+}
+
+// tag sets the scenario of an output whose schedule didn't, where
+// it can be known: jobs with a single scenario tag every output with
+// it. Otherwise only the schedule knows which call made an output, so
+// outputs it doesn't tag are left untagged
+func (j *Job) tag(o *Output) {
+	if o.Scenario == "" && len(j.Scenarios) == 1 {
+		o.Scenario = j.Scenarios[0].Name
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff"
+)
+
+func TestJob_Scenario(t *testing.T) {
+	RPCCommand = "Server.Run"
+
+	t.Run("no scenarios", func(t *testing.T) {
+		s := new(Job).scenario()
+
+		if s.Name != "" {
+			t.Errorf("expected unnamed scenario, received %q", s.Name)
+		}
+
+		if s.method() != RPCCommand {
+			t.Errorf("expected %q, received %q", RPCCommand, s.method())
+		}
+	})
+
+	t.Run("weighted", func(t *testing.T) {
+		j := Job{Scenarios: []Scenario{
+			{Name: "browse", Method: "Server.Browse", Weight: 6},
+			{Name: "search", Method: "Server.Search", Weight: 3},
+			{Name: "checkout"},
+		}}
+
+		n := 10000
+		picked := make(map[string]int)
+		for i := 0; i < n; i++ {
+			picked[j.scenario().Name]++
+		}
+
+		for name, expect := range map[string]float64{"browse": 0.6, "search": 0.3, "checkout": 0.1} {
+			received := float64(picked[name]) / float64(n)
+			if math.Abs(expect-received) > 0.03 {
+				t.Errorf("%s: expected around %.2f of calls, received %.2f", name, expect, received)
+			}
+		}
+	})
+}
+
+func TestJob_Tag(t *testing.T) {
+	one := []Scenario{{Name: "browse"}}
+	two := []Scenario{{Name: "browse"}, {Name: "search"}}
+
+	for _, test := range []struct {
+		name      string
+		scenarios []Scenario
+		scenario  string
+		expect    string
+	}{
+		{"tagged by the schedule", two, "search", "search"},
+		{"single scenario", one, "", "browse"},
+		{"several scenarios", two, "", ""},
+		{"no scenarios", nil, "", ""},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			o := &Output{Scenario: test.scenario}
+
+			(&Job{Scenarios: test.scenarios}).tag(o)
+			if test.expect != o.Scenario {
+				t.Errorf("expected %q, received %q", test.expect, o.Scenario)
+			}
+		})
+	}
+}
+
+func TestFakeRuntime_Scenarios(t *testing.T) {
+	expoBackoff = backoff.NewExponentialBackOff()
+	expoBackoff.MaxElapsedTime = time.Second
+
+	logDir = &td
+
+	outputs := make(chan Output)
+	received := make(chan map[string]int)
+
+	go func() {
+		m := make(map[string]int)
+		for o := range outputs {
+			m[o.Scenario]++
+		}
+
+		received <- m
+	}()
+
+	j := Job{
+		Name:      "fake",
+		Duration:  1,
+		Pacing:    10,
+		Users:     5,
+		Transport: TransportGRPC,
+		Scenarios: []Scenario{{Name: "browse", Weight: 3}, {Name: "checkout"}},
+		runtime:   new(fakeRuntime),
+	}
+
+	err := j.Start(chanSink(outputs))
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	close(outputs)
+
+	m := <-received
+	if m[""] > 0 {
+		t.Errorf("expected every output to be tagged, %d weren't", m[""])
+	}
+
+	if m["browse"] <= m["checkout"] {
+		t.Errorf("expected more browse outputs than checkout, received %v", m)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-lo/agent/agent"
+)
+
+const (
+	// drainInterval is how often a draining job checks whether
+	// its calls have finished
+	drainInterval = 10 * time.Millisecond
+)
+
+// wait waits for the schedule to exit, recording how it exited and the
+// phase of shutdown it exited in, and then closes j.exited. These are
+// recorded under the job's lock, as status reads them
+func (j *Job) wait() {
+	defer close(j.exited)
+
+	e, err := j.runtime.wait()
+	if err != nil {
+		log.Print(err)
+	}
+
+	phase := agent.Phase(atomic.LoadInt32(&j.phase))
+
+	j.mutex.Lock()
+	j.exit = e
+	j.exitPhase = phase
+	j.oomKilled = e.oomKilled
+	j.mutex.Unlock()
+
+	if err == nil && !e.success() {
+		log.Printf("%s exited while %s: %s", j.Name, phase, e)
+	}
+}
+
+// drain waits for calls in flight to finish, for up to j.GracePeriod
+// seconds, or until the schedule exits
+func (j *Job) drain() {
+	j.setPhase(agent.Phase_DRAINING)
+
+	deadline := time.After(time.Duration(j.GracePeriod) * time.Second)
+
+	ticker := time.NewTicker(drainInterval)
+	defer ticker.Stop()
+
+	for atomic.LoadInt64(&j.calling) > 0 {
+		select {
+		case <-deadline:
+			log.Printf("%s: %d calls still in flight after grace period", j.Name, atomic.LoadInt64(&j.calling))
+
+			return
+
+		case <-j.exited:
+			return
+
+		case <-ticker.C:
+		}
+	}
+}
+
+// stopProcess asks the schedule to exit, giving it j.StopTimeout seconds
+// to do so (and to flush its output) before killing it. A schedule which
+// has already exited is left alone.
+//
+// Either way, the runtime then cleans up whatever the schedule left behind
+func (j *Job) stopProcess() {
+	select {
+	case <-j.exited:
+
+	default:
+		j.setPhase(agent.Phase_TERMINATING)
+		j.stopSchedule(false)
+
+		select {
+		case <-j.exited:
+
+		case <-time.After(time.Duration(j.StopTimeout) * time.Second):
+			j.setPhase(agent.Phase_KILLING)
+			j.stopSchedule(true)
+
+			<-j.exited
+		}
+	}
+
+	j.runtime.cleanup()
+}
+
+func (j *Job) stopSchedule(force bool) {
+	err := j.runtime.stop(force)
+	if err != nil {
+		log.Print(err)
+	}
+}
+
+func (j *Job) setPhase(p agent.Phase) {
+	atomic.StoreInt32(&j.phase, int32(p))
+}
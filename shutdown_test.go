@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/go-lo/agent/agent"
+)
+
+func startProcess(t *testing.T, name string, args ...string) (j *Job) {
+	cmd := exec.Command(name, args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	err := cmd.Start()
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	j = &Job{
+		Binary:      name,
+		StopTimeout: 1,
+		GracePeriod: 1,
+		runtime:     &localRuntime{process: cmd.Process},
+		exited:      make(chan struct{}),
+	}
+
+	j.setPhase(agent.Phase_RUNNING)
+	go j.wait()
+
+	return
+}
+
+func TestJob_StopProcess(t *testing.T) {
+	t.Run("exits on SIGTERM", func(t *testing.T) {
+		j := startProcess(t, "sleep", "10")
+		j.stopProcess()
+
+		if agent.Phase_TERMINATING != j.exitPhase {
+			t.Errorf("expected %s, received %s", agent.Phase_TERMINATING, j.exitPhase)
+		}
+	})
+
+	t.Run("ignores SIGTERM", func(t *testing.T) {
+		j := startProcess(t, "sh", "-c", `trap "" TERM; while true; do sleep 0.1; done`)
+
+		// give sh time to set its trap up
+		time.Sleep(100 * time.Millisecond)
+
+		start := time.Now()
+		j.stopProcess()
+
+		if agent.Phase_KILLING != j.exitPhase {
+			t.Errorf("expected %s, received %s", agent.Phase_KILLING, j.exitPhase)
+		}
+
+		if time.Since(start) < time.Second {
+			t.Errorf("expected process to be given StopTimeout to exit")
+		}
+	})
+
+	t.Run("already exited", func(t *testing.T) {
+		j := startProcess(t, "true")
+		<-j.exited
+
+		j.stopProcess()
+
+		if agent.Phase_RUNNING != j.exitPhase {
+			t.Errorf("expected %s, received %s", agent.Phase_RUNNING, j.exitPhase)
+		}
+	})
+}
+
+func TestJob_StatusWhileExiting(t *testing.T) {
+	j := startProcess(t, "sleep", "0.1")
+
+	// Run with -race, this catches wait recording how the schedule
+	// exited without holding the job's lock
+	for {
+		j.status()
+
+		select {
+		case <-j.exited:
+			js := j.status()
+			if agent.Phase_RUNNING != js.Exited {
+				t.Errorf("expected %s, received %s", agent.Phase_RUNNING, js.Exited)
+			}
+
+			return
+
+		default:
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
+func TestJob_Drain(t *testing.T) {
+	t.Run("calls finish", func(t *testing.T) {
+		j := startProcess(t, "sleep", "10")
+		defer j.stopProcess()
+
+		atomic.StoreInt64(&j.calling, 1)
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			atomic.StoreInt64(&j.calling, 0)
+		}()
+
+		start := time.Now()
+		j.drain()
+
+		if time.Since(start) > 500*time.Millisecond {
+			t.Errorf("expected drain to return once calls finish, took %s", time.Since(start))
+		}
+	})
+
+	t.Run("calls outlast grace period", func(t *testing.T) {
+		j := startProcess(t, "sleep", "10")
+		defer j.stopProcess()
+
+		atomic.StoreInt64(&j.calling, 1)
+
+		start := time.Now()
+		j.drain()
+
+		if time.Since(start) < time.Second {
+			t.Errorf("expected drain to wait out the grace period, took %s", time.Since(start))
+		}
+	})
+
+	t.Run("schedule exits while draining", func(t *testing.T) {
+		j := startProcess(t, "sleep", "0.1")
+
+		atomic.StoreInt64(&j.calling, 1)
+		j.drain()
+		<-j.exited
+
+		if agent.Phase_DRAINING != j.exitPhase {
+			t.Errorf("expected %s, received %s", agent.Phase_DRAINING, j.exitPhase)
+		}
+	})
+}
+
+func TestJob_ProcessGroup(t *testing.T) {
+	dir, err := ioutil.TempDir(td, "process-group")
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	// A schedule which starts a helper, and then ignores SIGTERM
+	// so that the helper outlives the schedule's own shutdown
+	pidfile := filepath.Join(dir, "helper.pid")
+	script := filepath.Join(dir, "schedule.sh")
+
+	err = ioutil.WriteFile(script, []byte(fmt.Sprintf("#!/bin/sh\nsleep 30 &\necho $! > %s\ntrap '' TERM\nwhile true; do sleep 0.1; done\n", pidfile)), 0755)
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	j := &Job{
+		StopTimeout: 1,
+		bin:         binary{Path: script},
+	}
+
+	err = j.execute()
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	var pid int
+	for i := 0; i < 50 && pid == 0; i++ {
+		time.Sleep(20 * time.Millisecond)
+
+		b, _ := ioutil.ReadFile(pidfile)
+		pid, _ = strconv.Atoi(strings.TrimSpace(string(b)))
+	}
+
+	if pid == 0 {
+		t.Fatalf("helper never started")
+	}
+
+	j.stopProcess()
+
+	if agent.Phase_KILLING != j.exitPhase {
+		t.Errorf("expected %s, received %s", agent.Phase_KILLING, j.exitPhase)
+	}
+
+	// Signal 0 checks whether a process exists without signalling it
+	if syscall.Kill(pid, 0) != syscall.ESRCH {
+		t.Errorf("expected helper %d to have been killed and reaped", pid)
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	trustedKeysFile = flag.String("trusted-keys", "", "file of base64 encoded ed25519 public keys, one per line. When set, schedule binaries must be signed by one of them")
+
+	// trustedKeys are the keys schedule binaries must be signed by.
+	// When there are none, binaries aren't checked
+	trustedKeys []ed25519.PublicKey
+)
+
+// loadTrustedKeys reads the public keys in path into trustedKeys. Blank
+// lines, and lines starting with #, are ignored
+func loadTrustedKeys(path string) (err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+
+	defer f.Close()
+
+	keys := make([]ed25519.PublicKey, 0)
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		k, err := base64.StdEncoding.DecodeString(text)
+		if err != nil || len(k) != ed25519.PublicKeySize {
+			return fmt.Errorf("%s:%d: not a base64 encoded ed25519 public key", path, line)
+		}
+
+		keys = append(keys, ed25519.PublicKey(k))
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return
+	}
+
+	if len(keys) == 0 {
+		return fmt.Errorf("%s: no trusted keys", path)
+	}
+
+	trustedKeys = keys
+
+	return
+}
+
+// decodeSignature decodes a base64 encoded detached ed25519 signature
+func decodeSignature(s string) (sig []byte, err error) {
+	if s == "" {
+		return
+	}
+
+	sig, err = base64.StdEncoding.DecodeString(s)
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("binary signature must be a base64 encoded ed25519 signature")
+	}
+
+	return
+}
+
+// verify checks that the binary was signed by one of trustedKeys. Where
+// the agent has no trusted keys, every binary passes
+func (b binary) verify() (err error) {
+	if len(trustedKeys) == 0 {
+		return
+	}
+
+	err = b.signed()
+	if err != nil {
+		return
+	}
+
+	data, err := ioutil.ReadFile(b.Path)
+	if err != nil {
+		return
+	}
+
+	return b.check(data)
+}
+
+// signed returns an error if the agent has trusted keys and the binary
+// has no signature to check against them. Unlike verify, it doesn't
+// need the binary itself, so works for binaries yet to be fetched
+func (b binary) signed() error {
+	if len(trustedKeys) > 0 && len(b.Signature) == 0 {
+		return fmt.Errorf("binary %s is not signed, and this agent only runs signed binaries", b.location())
+	}
+
+	return nil
+}
+
+// check checks that data, the binary's contents, were signed by one
+// of trustedKeys
+func (b binary) check(data []byte) (err error) {
+	for _, k := range trustedKeys {
+		if ed25519.Verify(k, data, b.Signature) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("binary %s is not signed by a trusted key", b.location())
+}
+
+// install copies the binary into dir, which only the agent can read,
+// verifying the copy as it goes, and returns the copy's path. Running
+// the copy means that what's run is what was verified, however the
+// original changes in the meantime
+func (b binary) install(dir string) (path string, err error) {
+	err = b.signed()
+	if err != nil {
+		return
+	}
+
+	// Bare names are run from the agent's PATH
+	src, err := exec.LookPath(b.Path)
+	if err != nil {
+		return
+	}
+
+	data, err := ioutil.ReadFile(src)
+	if err != nil {
+		return
+	}
+
+	err = b.check(data)
+	if err != nil {
+		return
+	}
+
+	path = filepath.Join(dir, filepath.Base(b.Path))
+
+	err = ioutil.WriteFile(path, data, 0500)
+
+	return
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-lo/agent/agent"
+)
+
+func TestLoadTrustedKeys(t *testing.T) {
+	dir, err := ioutil.TempDir(td, "trusted-keys")
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	defer func() {
+		trustedKeys = nil
+	}()
+
+	pub, _, _ := ed25519.GenerateKey(nil)
+	key := base64.StdEncoding.EncodeToString(pub)
+
+	for _, test := range []struct {
+		name        string
+		contents    string
+		expectKeys  int
+		expectError bool
+	}{
+		{"one key", key + "\n", 1, false},
+		{"comments and blank lines", "# ci\n" + key + "\n\n# release\n" + key + "\n", 2, false},
+		{"not a key", "foo\n", 0, true},
+		{"short key", base64.StdEncoding.EncodeToString(pub[:16]) + "\n", 0, true},
+		{"no keys", "# nothing here\n", 0, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			trustedKeys = nil
+
+			path := filepath.Join(dir, strings.Replace(test.name, " ", "-", -1))
+			ioutil.WriteFile(path, []byte(test.contents), 0644)
+
+			err := loadTrustedKeys(path)
+			if test.expectError && err == nil {
+				t.Errorf("expected error")
+			}
+
+			if !test.expectError && err != nil {
+				t.Errorf("unexpected error %+v", err)
+			}
+
+			if test.expectKeys != len(trustedKeys) {
+				t.Errorf("expected %d keys, received %d", test.expectKeys, len(trustedKeys))
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		err := loadTrustedKeys(filepath.Join(dir, "nonsuch"))
+		if err == nil {
+			t.Errorf("expected error")
+		}
+	})
+}
+
+func TestBinary_Verify(t *testing.T) {
+	dir, err := ioutil.TempDir(td, "verify")
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	_, other, _ := ed25519.GenerateKey(nil)
+
+	contents := []byte("#!/bin/sh\necho hello\n")
+
+	path := filepath.Join(dir, "schedule")
+	ioutil.WriteFile(path, contents, 0755)
+
+	tampered := filepath.Join(dir, "tampered")
+	ioutil.WriteFile(tampered, append(contents, '#'), 0755)
+
+	signed := ed25519.Sign(priv, contents)
+
+	for _, test := range []struct {
+		name        string
+		keys        []ed25519.PublicKey
+		b           binary
+		expectError bool
+	}{
+		{"no trusted keys", nil, binary{Path: path}, false},
+		{"signed", []ed25519.PublicKey{pub}, binary{Path: path, Signature: signed}, false},
+		{"unsigned", []ed25519.PublicKey{pub}, binary{Path: path}, true},
+		{"signed by an untrusted key", []ed25519.PublicKey{pub}, binary{Path: path, Signature: ed25519.Sign(other, contents)}, true},
+		{"tampered with", []ed25519.PublicKey{pub}, binary{Path: tampered, Signature: signed}, true},
+		{"missing", []ed25519.PublicKey{pub}, binary{Path: filepath.Join(dir, "nonsuch"), Signature: signed}, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			trustedKeys = test.keys
+			defer func() {
+				trustedKeys = nil
+			}()
+
+			err := test.b.verify()
+			if test.expectError && err == nil {
+				t.Errorf("expected error")
+			}
+
+			if !test.expectError && err != nil {
+				t.Errorf("unexpected error %+v", err)
+			}
+		})
+	}
+
+	t.Run("refused by Create", func(t *testing.T) {
+		trustedKeys = []ed25519.PublicKey{pub}
+		defer func() {
+			trustedKeys = nil
+		}()
+
+		q := NewQueue(discardSink{})
+
+		r, err := q.Create(context.Background(), &agent.Payload{
+			Job: &agent.Job{
+				Name:      "unsigned",
+				Duration:  1,
+				Binary:    path,
+				Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(other, contents)),
+			},
+		})
+
+		if err != nil {
+			t.Fatalf("unexpected error %+v", err)
+		}
+
+		if !r.Error {
+			t.Errorf("expected job to be refused")
+		}
+
+		if !strings.Contains(r.Output, "not signed by a trusted key") {
+			t.Errorf("expected %q to explain the refusal", r.Output)
+		}
+	})
+	t.Run("unsigned urls refused by Create", func(t *testing.T) {
+		trustedKeys = []ed25519.PublicKey{pub}
+		defer func() {
+			trustedKeys = nil
+		}()
+
+		q := NewQueue(discardSink{})
+
+		r, err := q.Create(context.Background(), &agent.Payload{
+			Job: &agent.Job{
+				Name:     "unsigned-url",
+				Duration: 1,
+				Binary:   "http://127.0.0.1:1/schedule",
+				Sha256:   strings.Repeat("0", 64),
+			},
+		})
+
+		if err != nil {
+			t.Fatalf("unexpected error %+v", err)
+		}
+
+		if !r.Error {
+			t.Errorf("expected job to be refused")
+		}
+
+		if !strings.Contains(r.Output, "not signed") {
+			t.Errorf("expected %q to explain the refusal", r.Output)
+		}
+	})
+
+	t.Run("containers refused by Create", func(t *testing.T) {
+		trustedKeys = []ed25519.PublicKey{pub}
+		defer func() {
+			trustedKeys = nil
+		}()
+
+		q := NewQueue(discardSink{})
+
+		r, err := q.Create(context.Background(), &agent.Payload{
+			Job: &agent.Job{
+				Name:      "container",
+				Duration:  1,
+				Container: "somecontainer:latest",
+			},
+		})
+
+		if err != nil {
+			t.Fatalf("unexpected error %+v", err)
+		}
+
+		if !r.Error {
+			t.Errorf("expected job to be refused")
+		}
+	})
+}
+
+func TestBinary_Install(t *testing.T) {
+	dir, err := ioutil.TempDir(td, "install")
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	pub, priv, _ := ed25519.GenerateKey(nil)
+
+	trustedKeys = []ed25519.PublicKey{pub}
+	defer func() {
+		trustedKeys = nil
+	}()
+
+	contents := []byte("#!/bin/sh\necho hello\n")
+
+	path := filepath.Join(dir, "schedule")
+	ioutil.WriteFile(path, contents, 0755)
+
+	b := binary{Path: path, Signature: ed25519.Sign(priv, contents)}
+
+	private, err := ioutil.TempDir(dir, "private")
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	installed, err := b.install(private)
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	// Changing the original doesn't change what's run
+	ioutil.WriteFile(path, append(contents, '#'), 0755)
+
+	data, err := ioutil.ReadFile(installed)
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	if string(contents) != string(data) {
+		t.Errorf("expected %q, received %q", contents, data)
+	}
+
+	fi, err := os.Stat(installed)
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	if fi.Mode().Perm() != 0500 {
+		t.Errorf("expected %s, received %s", os.FileMode(0500), fi.Mode().Perm())
+	}
+
+	_, err = b.install(private)
+	if err == nil {
+		t.Errorf("expected a tampered with binary to be refused")
+	}
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-lo/agent/agent"
+)
+
+const (
+	// SinkCollector sends outputs to the agent's collector: every job's
+	// outputs, as lines of json, on the agent's stdout
+	SinkCollector = "collector"
+
+	// SinkFile appends outputs, as lines of json, to a file
+	SinkFile = "file"
+
+	// SinkPrometheus counts outputs in the metrics the agent serves
+	// for prometheus to scrape
+	SinkPrometheus = "prometheus"
+
+	// SinkWebhook posts outputs, in batches of json arrays, to a URL
+	SinkWebhook = "webhook"
+
+	// sinkBuffer is the number of outputs a sink can fall behind
+	// by before outputs are dropped
+	sinkBuffer = 1024
+)
+
+// Sink receives the outputs of jobs. A Sink's Write is only ever
+// called by one goroutine at a time per job, but Sinks shared between
+// jobs, such as the collector, must cope with jobs running side by side
+type Sink interface {
+	Write(o Output) error
+	Close() error
+}
+
+// SinkConfig is where a job sends its outputs. Path is the file a
+// SinkFile writes to, relative to the job's log directory, which is
+// results.jsonl unless set, and URL the http or https URL a SinkWebhook
+// posts to
+type SinkConfig struct {
+	Type string `json:"type"`
+	Path string `json:"path"`
+	URL  string `json:"url"`
+}
+
+// validate returns an error if s can't be made into a Sink
+func (s SinkConfig) validate() (err error) {
+	switch s.Type {
+	case SinkCollector:
+	case SinkFile:
+		if filepath.IsAbs(s.Path) || escapes(s.Path) {
+			err = fmt.Errorf("file sink path %q must be relative to the job's log directory", s.Path)
+		}
+	case SinkPrometheus:
+		if *metricsAddr == "" {
+			err = fmt.Errorf("this agent doesn't serve prometheus metrics")
+		}
+	case SinkWebhook:
+		u, perr := url.Parse(s.URL)
+		if perr != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			err = fmt.Errorf("webhook sink needs an http or https url, received %q", s.URL)
+		}
+	default:
+		err = fmt.Errorf("unknown sink %q", s.Type)
+	}
+
+	return
+}
+
+// newSink returns the Sink s configures for job j. collector is the
+// agent's collector, which is shared, so isn't closed with the job
+func (j *Job) newSink(s SinkConfig, collector Sink) (Sink, error) {
+	// Jobs don't have to come from a Payload, so their sinks are
+	// checked here too
+	err := s.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	switch s.Type {
+	case SinkCollector:
+		return unclosed{collector}, nil
+
+	case SinkFile:
+		path := s.Path
+		if path == "" {
+			path = "results.jsonl"
+		}
+
+		return newFileSink(filepath.Join(j.logDir(), path))
+
+	case SinkPrometheus:
+		return promMetrics.sink(j.Name), nil
+
+	case SinkWebhook:
+		return newWebhookSink(s.URL), nil
+	}
+
+	return nil, fmt.Errorf("unknown sink %q", s.Type)
+}
+
+// escapes returns whether path, were it relative, could lead out
+// of the directory it's relative to
+func escapes(path string) bool {
+	for _, elem := range strings.Split(filepath.ToSlash(path), "/") {
+		if elem == ".." {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newSinks returns a fanout to the job's sinks; by default,
+// just collector
+func (j *Job) newSinks(collector Sink) (f *fanout, err error) {
+	configs := j.Sinks
+	if len(configs) == 0 {
+		configs = []SinkConfig{{Type: SinkCollector}}
+	}
+
+	sinks := make([]Sink, 0, len(configs))
+	for _, c := range configs {
+		var s Sink
+
+		s, err = j.newSink(c, collector)
+		if err != nil {
+			for _, s := range sinks {
+				s.Close()
+			}
+
+			return
+		}
+
+		sinks = append(sinks, s)
+	}
+
+	return newFanout(configs, sinks), nil
+}
+
+// unclosed wraps a shared Sink so that closing it does nothing
+type unclosed struct {
+	Sink
+}
+
+func (unclosed) Close() error {
+	return nil
+}
+
+// jsonSink writes outputs, as lines of json, to w. It's safe to share
+type jsonSink struct {
+	mutex sync.Mutex
+	w     io.Writer
+	enc   *json.Encoder
+}
+
+// newJSONSink returns a jsonSink writing to w
+func newJSONSink(w io.Writer) *jsonSink {
+	return &jsonSink{
+		w:   w,
+		enc: json.NewEncoder(w),
+	}
+}
+
+func (s *jsonSink) Write(o Output) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.enc.Encode(o)
+}
+
+// Close closes the writer of s, if it can be closed
+func (s *jsonSink) Close() error {
+	if c, ok := s.w.(io.Closer); ok {
+		return c.Close()
+	}
+
+	return nil
+}
+
+// newFileSink returns a jsonSink appending to the file at path
+func newFileSink(path string) (s *jsonSink, err error) {
+	err = os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		return
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return
+	}
+
+	return newJSONSink(f), nil
+}
+
+// fanout sends each output published to it on to every one of a job's
+// sinks. Each sink is written to from its own goroutine, through its own
+// buffer, so that a slow or failing sink can't hold up the job, or the
+// other sinks: a sink which falls too far behind misses outputs.
+//
+// The collector is the exception. It spools whatever it can't write
+// straight away, so never falls far behind, and is waited on rather than
+// missing anything
+type fanout struct {
+	mutex   sync.RWMutex
+	closed  bool
+	outlets []*outlet
+	wg      sync.WaitGroup
+}
+
+// outlet is a sink of a fanout, and the counts of outputs it was
+// sent, couldn't be sent, and which it failed to write
+type outlet struct {
+	config    SinkConfig
+	sink      Sink
+	c         chan Output
+	blocking  bool
+	delivered int64
+	dropped   int64
+	failed    int64
+}
+
+// newFanout returns a fanout to sinks, as configured by configs
+func newFanout(configs []SinkConfig, sinks []Sink) (f *fanout) {
+	f = new(fanout)
+
+	for i, s := range sinks {
+		o := &outlet{
+			config:   configs[i],
+			sink:     s,
+			c:        make(chan Output, sinkBuffer),
+			blocking: configs[i].Type == SinkCollector,
+		}
+
+		f.outlets = append(f.outlets, o)
+
+		f.wg.Add(1)
+		go f.drain(o)
+	}
+
+	return
+}
+
+// drain writes outputs to o's sink until o is closed, then closes
+// the sink
+func (f *fanout) drain(o *outlet) {
+	defer f.wg.Done()
+
+	for out := range o.c {
+		err := o.sink.Write(out)
+		if err != nil {
+			// Only log the first failure, rather than one per output
+			if atomic.AddInt64(&o.failed, 1) == 1 {
+				log.Printf("%s sink: %+v", o.config.Type, err)
+			}
+
+			continue
+		}
+
+		atomic.AddInt64(&o.delivered, 1)
+	}
+
+	err := o.sink.Close()
+	if err != nil {
+		log.Printf("%s sink: %+v", o.config.Type, err)
+	}
+}
+
+// publish sends out to every sink, without blocking on any but the
+// collector. A nil, or closed, fanout sends nothing
+func (f *fanout) publish(out Output) {
+	if f == nil {
+		return
+	}
+
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	if f.closed {
+		return
+	}
+
+	for _, o := range f.outlets {
+		if o.blocking {
+			o.c <- out
+
+			continue
+		}
+
+		select {
+		case o.c <- out:
+		default:
+			atomic.AddInt64(&o.dropped, 1)
+		}
+	}
+}
+
+// status returns the counts of each of the fanout's sinks
+func (f *fanout) status() (ss []*agent.SinkStatus) {
+	if f == nil {
+		return nil
+	}
+
+	for _, o := range f.outlets {
+		ss = append(ss, &agent.SinkStatus{
+			Sink:      sinkProto(o.config),
+			Delivered: uint64(atomic.LoadInt64(&o.delivered)),
+			Dropped:   uint64(atomic.LoadInt64(&o.dropped)),
+			Failed:    uint64(atomic.LoadInt64(&o.failed)),
+		})
+	}
+
+	return
+}
+
+// close stops the fanout, giving each sink until timeout to write what
+// it's been sent, and close
+func (f *fanout) close(timeout time.Duration) {
+	if f == nil {
+		return
+	}
+
+	f.mutex.Lock()
+	f.closed = true
+	for _, o := range f.outlets {
+		close(o.c)
+	}
+	f.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		f.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		log.Print("timed out waiting for sinks to flush")
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// maxMetricSeries caps the number of job, URL, method and status
+	// series the agent serves, so that calling a different URL every time
+	// can't eat the agent's memory, or prometheus'. Results past it are
+	// counted under the URL "other" where that series already exists,
+	// and otherwise aren't counted at all
+	maxMetricSeries = 10000
+)
+
+var (
+	metricsAddr = flag.String("metrics", "", "address to serve prometheus metrics on, at /metrics; unset, metrics aren't served")
+
+	// promMetrics are the metrics jobs with a prometheus sink count
+	// their results in
+	promMetrics = newMetrics()
+
+	// latencyBuckets are the upper bounds, in seconds, of the
+	// buckets of the result latency histogram
+	latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+)
+
+// seriesKey identifies a series of metrics
+type seriesKey struct {
+	job    string
+	url    string
+	method string
+	status int
+}
+
+// series are the metrics for a seriesKey
+type series struct {
+	results int64
+	errors  int64
+	bytes   int64
+	buckets []int64
+	sum     float64
+}
+
+// metrics counts results for prometheus, serving them in its text
+// exposition format. A job's series are served for as long as it has a
+// sink open, counted in sinks, and removed once it finishes
+type metrics struct {
+	mutex     sync.Mutex
+	series    map[seriesKey]*series
+	sinks     map[string]int
+	uncounted int64
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		series: make(map[seriesKey]*series),
+		sinks:  make(map[string]int),
+	}
+}
+
+// sink returns a promSink counting the results of job in m
+func (m *metrics) sink(job string) promSink {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.sinks[job]++
+
+	return promSink{m: m, job: job}
+}
+
+// close closes a sink of job's, removing the job's series once it
+// has none left open
+func (m *metrics) close(job string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.sinks[job]--
+	if m.sinks[job] > 0 {
+		return
+	}
+
+	delete(m.sinks, job)
+
+	for k := range m.series {
+		if k.job == job {
+			delete(m.series, k)
+		}
+	}
+}
+
+func (m *metrics) add(job string, o Output) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := seriesKey{job: job, url: o.URL, method: o.Method, status: o.Status}
+
+	s, ok := m.series[key]
+	if !ok && len(m.series) >= maxMetricSeries {
+		key.url = "other"
+		s, ok = m.series[key]
+	}
+
+	if !ok && len(m.series) >= maxMetricSeries {
+		m.uncounted++
+
+		return
+	}
+
+	if !ok {
+		s = &series{buckets: make([]int64, len(latencyBuckets))}
+		m.series[key] = s
+	}
+
+	s.results++
+	s.bytes += o.Size
+	if o.Error != nil {
+		s.errors++
+	}
+
+	seconds := o.Duration.Seconds()
+	s.sum += seconds
+
+	for i, le := range latencyBuckets {
+		if seconds <= le {
+			s.buckets[i]++
+		}
+	}
+}
+
+// ServeHTTP writes every series in prometheus' text format
+func (m *metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	keys := make([]seriesKey, 0, len(m.series))
+	for k := range m.series {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].labels() < keys[j].labels()
+	})
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	for _, counter := range []struct {
+		name  string
+		help  string
+		value func(*series) int64
+	}{
+		{"golo_results_total", "Results from schedules.", func(s *series) int64 { return s.results }},
+		{"golo_result_errors_total", "Results from schedules which errored.", func(s *series) int64 { return s.errors }},
+		{"golo_result_bytes_total", "Bytes transferred by schedules.", func(s *series) int64 { return s.bytes }},
+	} {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", counter.name, counter.help, counter.name)
+
+		for _, k := range keys {
+			fmt.Fprintf(w, "%s{%s} %d\n", counter.name, k.labels(), counter.value(m.series[k]))
+		}
+	}
+
+	fmt.Fprintf(w, "# HELP golo_results_uncounted_total Results which weren't counted, for want of room for their series.\n# TYPE golo_results_uncounted_total counter\ngolo_results_uncounted_total %d\n", m.uncounted)
+
+	fmt.Fprint(w, "# HELP golo_result_duration_seconds Latency of results from schedules.\n# TYPE golo_result_duration_seconds histogram\n")
+
+	for _, k := range keys {
+		s := m.series[k]
+
+		for i, le := range latencyBuckets {
+			fmt.Fprintf(w, "golo_result_duration_seconds_bucket{%s,le=%q} %d\n", k.labels(), strconv.FormatFloat(le, 'f', -1, 64), s.buckets[i])
+		}
+
+		fmt.Fprintf(w, "golo_result_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", k.labels(), s.results)
+		fmt.Fprintf(w, "golo_result_duration_seconds_sum{%s} %s\n", k.labels(), strconv.FormatFloat(s.sum, 'f', -1, 64))
+		fmt.Fprintf(w, "golo_result_duration_seconds_count{%s} %d\n", k.labels(), s.results)
+	}
+}
+
+// labels formats k as prometheus labels
+func (k seriesKey) labels() string {
+	return fmt.Sprintf("job_name=%s,url=%s,method=%s,status=\"%d\"", label(k.job), label(k.url), label(k.method), k.status)
+}
+
+// label quotes v as a prometheus label value
+func label(v string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v) + `"`
+}
+
+// promSink counts the results of job in m
+type promSink struct {
+	m   *metrics
+	job string
+}
+
+func (s promSink) Write(o Output) error {
+	s.m.add(s.job, o)
+
+	return nil
+}
+
+func (s promSink) Close() error {
+	s.m.close(s.job)
+
+	return nil
+}
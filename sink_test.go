@@ -0,0 +1,423 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-lo/go-lo"
+)
+
+// chanSink sends outputs down a channel
+type chanSink chan Output
+
+func (s chanSink) Write(o Output) error {
+	s <- o
+
+	return nil
+}
+
+func (chanSink) Close() error {
+	return nil
+}
+
+// discardSink throws outputs away
+type discardSink struct{}
+
+func (discardSink) Write(Output) error { return nil }
+func (discardSink) Close() error       { return nil }
+
+// blockedSink blocks writes until released
+type blockedSink chan struct{}
+
+func (s blockedSink) Write(Output) error {
+	<-s
+
+	return nil
+}
+
+func (blockedSink) Close() error {
+	return nil
+}
+
+// failingSink fails every write
+type failingSink struct{}
+
+func (failingSink) Write(Output) error { return fmt.Errorf("an error") }
+func (failingSink) Close() error       { return nil }
+
+func TestFanout(t *testing.T) {
+	blocked := make(blockedSink)
+	defer close(blocked)
+
+	outputs := make(chan Output, sinkBuffer*2)
+
+	f := newFanout(
+		[]SinkConfig{{Type: "blocked"}, {Type: "chan"}, {Type: "failing"}},
+		[]Sink{blocked, chanSink(outputs), failingSink{}},
+	)
+
+	n := sinkBuffer + 100
+
+	published := make(chan struct{})
+	go func() {
+		for i := 0; i < n; i++ {
+			f.publish(Output{})
+		}
+
+		close(published)
+	}()
+
+	select {
+	case <-published:
+	case <-time.After(time.Second):
+		t.Fatalf("expected a blocked sink not to hold up publishing")
+	}
+
+	start := time.Now()
+	f.close(100 * time.Millisecond)
+
+	if time.Since(start) > time.Second {
+		t.Errorf("expected close to give up on a blocked sink")
+	}
+
+	// Publishing to a closed fanout does nothing
+	f.publish(Output{})
+
+	status := f.status()
+
+	if status[0].Delivered != 0 || status[0].Dropped < 99 {
+		t.Errorf("blocked: expected no deliveries and at least %d dropped, received %+v", 99, status[0])
+	}
+
+	if status[1].Delivered+status[1].Dropped != uint64(n) || status[1].Failed != 0 {
+		t.Errorf("chan: expected %d delivered or dropped, received %+v", n, status[1])
+	}
+
+	if status[2].Delivered != 0 || status[2].Failed+status[2].Dropped != uint64(n) {
+		t.Errorf("failing: expected %d failed or dropped, received %+v", n, status[2])
+	}
+}
+
+func TestFanout_Collector(t *testing.T) {
+	n := sinkBuffer + 100
+
+	collector := gatedSink{gate: make(chan struct{}), out: make(chan Output, n)}
+
+	f := newFanout([]SinkConfig{{Type: SinkCollector}}, []Sink{collector})
+
+	published := make(chan struct{})
+	go func() {
+		for i := 0; i < n; i++ {
+			f.publish(Output{})
+		}
+
+		close(published)
+	}()
+
+	select {
+	case <-published:
+		t.Fatalf("expected the collector to be waited on, rather than skipped")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(collector.gate)
+	<-published
+
+	f.close(time.Second)
+
+	status := f.status()
+	if status[0].Delivered != uint64(n) || status[0].Dropped != 0 {
+		t.Errorf("expected %d delivered, received %+v", n, status[0])
+	}
+}
+
+func TestFanout_Nil(t *testing.T) {
+	var f *fanout
+
+	f.publish(Output{})
+	f.close(time.Millisecond)
+
+	if f.status() != nil {
+		t.Errorf("expected no status")
+	}
+}
+
+func TestSinkConfig_Validate(t *testing.T) {
+	defaultMetricsAddr := *metricsAddr
+	defer func() {
+		metricsAddr = &defaultMetricsAddr
+	}()
+
+	for _, test := range []struct {
+		name        string
+		config      SinkConfig
+		metrics     string
+		expectError bool
+	}{
+		{"collector", SinkConfig{Type: SinkCollector}, "", false},
+		{"file", SinkConfig{Type: SinkFile, Path: "results/loadtest.jsonl"}, "", false},
+		{"file, absolute path", SinkConfig{Type: SinkFile, Path: "/tmp/results.jsonl"}, "", true},
+		{"file, outside the log directory", SinkConfig{Type: SinkFile, Path: "../results.jsonl"}, "", true},
+		{"file, default path", SinkConfig{Type: SinkFile}, "", false},
+		{"prometheus", SinkConfig{Type: SinkPrometheus}, ":9100", false},
+		{"prometheus, without metrics", SinkConfig{Type: SinkPrometheus}, "", true},
+		{"webhook", SinkConfig{Type: SinkWebhook, URL: "https://example.com/results"}, "", false},
+		{"webhook, without a url", SinkConfig{Type: SinkWebhook}, "", true},
+		{"webhook, with a file url", SinkConfig{Type: SinkWebhook, URL: "file:///tmp/results"}, "", true},
+		{"unknown", SinkConfig{Type: "carrier-pigeon"}, "", true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			metrics := test.metrics
+			metricsAddr = &metrics
+
+			err := test.config.validate()
+			if test.expectError && err == nil {
+				t.Errorf("expected error")
+			}
+
+			if !test.expectError && err != nil {
+				t.Errorf("unexpected error %+v", err)
+			}
+		})
+	}
+}
+
+func TestJob_NewSinks(t *testing.T) {
+	logDir = &td
+
+	outputs := make(chan Output, 1)
+
+	t.Run("default", func(t *testing.T) {
+		f, err := (&Job{Name: "sinks"}).newSinks(chanSink(outputs))
+		if err != nil {
+			t.Fatalf("unexpected error %+v", err)
+		}
+
+		f.publish(Output{Output: golo.Output{URL: "http://example.com"}})
+		f.close(time.Second)
+
+		if o := <-outputs; o.URL != "http://example.com" {
+			t.Errorf("expected %q, received %q", "http://example.com", o.URL)
+		}
+	})
+
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(td, "sinks-id", "results.jsonl")
+		os.Remove(path)
+
+		f, err := (&Job{ID: "sinks-id", Name: "sinks", Sinks: []SinkConfig{{Type: SinkFile}}}).newSinks(chanSink(outputs))
+		if err != nil {
+			t.Fatalf("unexpected error %+v", err)
+		}
+
+		f.publish(Output{Output: golo.Output{URL: "http://example.com/1"}})
+		f.publish(Output{Output: golo.Output{URL: "http://example.com/2"}})
+		f.close(time.Second)
+
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatalf("unexpected error %+v", err)
+		}
+
+		defer file.Close()
+
+		var urls []string
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			o := new(Output)
+
+			err = json.Unmarshal(scanner.Bytes(), o)
+			if err != nil {
+				t.Fatalf("unexpected error %+v", err)
+			}
+
+			urls = append(urls, o.URL)
+		}
+
+		expect := "http://example.com/1,http://example.com/2"
+		if expect != strings.Join(urls, ",") {
+			t.Errorf("expected %q, received %q", expect, strings.Join(urls, ","))
+		}
+
+		select {
+		case <-outputs:
+			t.Errorf("expected the collector only to receive outputs when it's a sink")
+		default:
+		}
+	})
+
+	t.Run("webhook, with a file url", func(t *testing.T) {
+		_, err := (&Job{Name: "sinks", Sinks: []SinkConfig{{Type: SinkWebhook, URL: "file:///etc/passwd"}}}).newSinks(chanSink(outputs))
+		if err == nil {
+			t.Errorf("expected error")
+		}
+	})
+
+	t.Run("file, outside the log directory", func(t *testing.T) {
+		_, err := (&Job{Name: "sinks", Sinks: []SinkConfig{{Type: SinkFile, Path: "../../results.jsonl"}}}).newSinks(chanSink(outputs))
+		if err == nil {
+			t.Errorf("expected error")
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		_, err := (&Job{Name: "sinks", Sinks: []SinkConfig{{Type: SinkFile}, {Type: "carrier-pigeon"}}}).newSinks(chanSink(outputs))
+		if err == nil {
+			t.Errorf("expected error")
+		}
+	})
+}
+
+func TestWebhookSink(t *testing.T) {
+	var (
+		mutex   sync.Mutex
+		batches []int
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var outputs []Output
+
+		err := json.NewDecoder(r.Body).Decode(&outputs)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		mutex.Lock()
+		batches = append(batches, len(outputs))
+		mutex.Unlock()
+	}))
+
+	defer server.Close()
+
+	s := newWebhookSink(server.URL)
+
+	for i := 0; i < webhookBatch+50; i++ {
+		err := s.Write(Output{})
+		if err != nil {
+			t.Fatalf("unexpected error %+v", err)
+		}
+	}
+
+	err := s.Close()
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	expect := fmt.Sprint([]int{webhookBatch, 50})
+	if expect != fmt.Sprint(batches) {
+		t.Errorf("expected batches of %s, received %v", expect, batches)
+	}
+}
+
+func TestWebhookSink_Failing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	defer server.Close()
+
+	s := newWebhookSink(server.URL)
+	s.Write(Output{})
+
+	if s.Close() == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	m := newMetrics()
+
+	for _, o := range []golo.Output{
+		{URL: "http://example.com/", Method: "GET", Status: 200, Size: 100, Duration: 20 * time.Millisecond},
+		{URL: "http://example.com/", Method: "GET", Status: 200, Size: 50, Duration: 3 * time.Second},
+		{URL: "http://example.com/", Method: "GET", Status: 500, Error: fmt.Errorf("an error")},
+	} {
+		promSink{m: m, job: "test"}.Write(Output{Output: o})
+	}
+
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	body := w.Body.String()
+
+	for _, expect := range []string{
+		`golo_results_total{job_name="test",url="http://example.com/",method="GET",status="200"} 2`,
+		`golo_results_total{job_name="test",url="http://example.com/",method="GET",status="500"} 1`,
+		`golo_result_errors_total{job_name="test",url="http://example.com/",method="GET",status="500"} 1`,
+		`golo_result_bytes_total{job_name="test",url="http://example.com/",method="GET",status="200"} 150`,
+		`golo_result_duration_seconds_bucket{job_name="test",url="http://example.com/",method="GET",status="200",le="0.025"} 1`,
+		`golo_result_duration_seconds_bucket{job_name="test",url="http://example.com/",method="GET",status="200",le="5"} 2`,
+		`golo_result_duration_seconds_bucket{job_name="test",url="http://example.com/",method="GET",status="200",le="+Inf"} 2`,
+		`golo_result_duration_seconds_sum{job_name="test",url="http://example.com/",method="GET",status="200"} 3.02`,
+		`golo_result_duration_seconds_count{job_name="test",url="http://example.com/",method="GET",status="200"} 2`,
+	} {
+		if !strings.Contains(body, expect+"\n") {
+			t.Errorf("expected %q in\n%s", expect, body)
+		}
+	}
+}
+
+func TestMetrics_Cap(t *testing.T) {
+	m := newMetrics()
+	s := m.sink("test")
+
+	for i := 0; i < maxMetricSeries+10; i++ {
+		s.Write(Output{Output: golo.Output{URL: fmt.Sprintf("http://example.com/%d", i), Method: "GET", Status: 200}})
+	}
+
+	if len(m.series) != maxMetricSeries {
+		t.Errorf("expected %d series, received %d", maxMetricSeries, len(m.series))
+	}
+
+	if m.uncounted != 10 {
+		t.Errorf("expected %d uncounted, received %d", 10, m.uncounted)
+	}
+}
+
+func TestMetrics_Close(t *testing.T) {
+	m := newMetrics()
+
+	first := m.sink("test")
+	second := m.sink("test")
+	other := m.sink("other")
+
+	for _, s := range []promSink{first, second, other} {
+		s.Write(Output{Output: golo.Output{URL: "http://example.com/", Method: "GET", Status: 200}})
+	}
+
+	// Jobs of the same name share series, which are kept until
+	// the last of them finishes
+	first.Close()
+
+	if len(m.series) != 2 {
+		t.Errorf("expected %d series, received %d", 2, len(m.series))
+	}
+
+	second.Close()
+
+	if len(m.series) != 1 {
+		t.Errorf("expected %d series, received %d", 1, len(m.series))
+	}
+}
+
+func TestLabel(t *testing.T) {
+	expect := `"say \"hi\"\\n\n"`
+	if received := label("say \"hi\"\\n\n"); expect != received {
+		t.Errorf("expected %q, received %q", expect, received)
+	}
+}
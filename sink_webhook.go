@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// webhookBatch is the most outputs a webhook sink posts at once
+	webhookBatch = 100
+
+	// webhookInterval is the longest a webhook sink holds on to
+	// outputs before posting them
+	webhookInterval = time.Second
+
+	// webhookTimeout is how long a webhook has to respond
+	webhookTimeout = 10 * time.Second
+)
+
+// webhookSink posts outputs, as json arrays, to a URL. Outputs are
+// batched, and posted once there are webhookBatch of them or they've
+// been held for webhookInterval
+type webhookSink struct {
+	mutex  sync.Mutex
+	url    string
+	client *http.Client
+	batch  []Output
+	err    error
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newWebhookSink(url string) (s *webhookSink) {
+	s = &webhookSink{
+		url: url,
+		client: &http.Client{
+			Timeout: webhookTimeout,
+		},
+		done: make(chan struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.tick()
+
+	return
+}
+
+// tick posts whatever has been batched up every webhookInterval
+func (s *webhookSink) tick() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(webhookInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+
+		case <-ticker.C:
+			s.mutex.Lock()
+			err := s.flush()
+			if err != nil && s.err == nil {
+				s.err = err
+			}
+			s.mutex.Unlock()
+		}
+	}
+}
+
+// Write batches o, posting the batch if it's full. Failures to post
+// batches on the ticker are returned by the next call to Write, so a
+// failure counts a whole batch, rather than each output in it
+func (s *webhookSink) Write(o Output) (err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err, s.err = s.err, nil
+
+	s.batch = append(s.batch, o)
+	if len(s.batch) >= webhookBatch {
+		ferr := s.flush()
+		if err == nil {
+			err = ferr
+		}
+	}
+
+	return
+}
+
+// Close posts anything left over
+func (s *webhookSink) Close() error {
+	close(s.done)
+	s.wg.Wait()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.flush()
+}
+
+// flush posts the current batch. Callers must hold the mutex
+func (s *webhookSink) flush() (err error) {
+	if len(s.batch) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(s.batch)
+	s.batch = nil
+
+	if err != nil {
+		return
+	}
+
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+
+	resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		err = fmt.Errorf("posting to %s: %s", s.url, resp.Status)
+	}
+
+	return
+}
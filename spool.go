@@ -0,0 +1,370 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// spoolSegment is the most outputs written to a spool file before
+	// starting another. Files are removed once replayed, so this bounds
+	// both the disk a replayed spool holds on to, and the outputs replayed
+	// twice should the agent restart part way through a file
+	spoolSegment = 1000
+
+	// spoolRetry is how long a spool waits to try again after
+	// its sink fails
+	spoolRetry = time.Second
+)
+
+var (
+	spoolBuffer = flag.Int("spool-buffer", 10000, "outputs to hold in memory while the collector falls behind, before spooling them to disk")
+)
+
+// spool sits in front of a Sink, such as the collector, which can be
+// slow or go away for a while. Writes never block: outputs are held in
+// memory, up to a limit, and past that appended to files on disk, to be
+// written to the sink, in order, as it recovers.
+//
+// Spool files survive the agent restarting, and are replayed by the next
+// spool using the same directory. Outputs held in memory don't
+type spool struct {
+	mutex  sync.Mutex
+	cond   *sync.Cond
+	sink   Sink
+	dir    string
+	max    int
+	closed bool
+
+	// memory holds outputs until it fills up, after which everything
+	// goes to disk until the spool files have all been replayed. Outputs
+	// in memory are always older than those on disk
+	memory []Output
+
+	// segments are the numbers of the spool files on disk, oldest first.
+	// w is the newest, being written to, and r the oldest, being read
+	segments []int
+	w        *os.File
+	written  int
+	r        *bufio.Reader
+	rfile    *os.File
+}
+
+// spooled is how outputs are written to disk. Output's Error doesn't
+// survive being marshalled and unmarshalled, so it's kept as a string,
+// and each field is copied across by hand
+type spooled struct {
+	SequenceID string        `json:"sequenceID"`
+	URL        string        `json:"url"`
+	Method     string        `json:"method"`
+	Status     int           `json:"status"`
+	Size       int64         `json:"size"`
+	Timestamp  time.Time     `json:"timestamp"`
+	Duration   time.Duration `json:"duration"`
+	Error      string        `json:"error,omitempty"`
+	Scenario   string        `json:"scenario,omitempty"`
+}
+
+// newSpooled returns o as it's written to disk
+func newSpooled(o Output) (sp spooled) {
+	sp = spooled{
+		SequenceID: o.SequenceID,
+		URL:        o.URL,
+		Method:     o.Method,
+		Status:     o.Status,
+		Size:       o.Size,
+		Timestamp:  o.Timestamp,
+		Duration:   o.Duration,
+		Scenario:   o.Scenario,
+	}
+
+	if o.Error != nil {
+		sp.Error = o.Error.Error()
+	}
+
+	return
+}
+
+// output is the inverse of newSpooled
+func (sp spooled) output() (o Output) {
+	o.SequenceID = sp.SequenceID
+	o.URL = sp.URL
+	o.Method = sp.Method
+	o.Status = sp.Status
+	o.Size = sp.Size
+	o.Timestamp = sp.Timestamp
+	o.Duration = sp.Duration
+	o.Scenario = sp.Scenario
+
+	if sp.Error != "" {
+		o.Error = errors.New(sp.Error)
+	}
+
+	return
+}
+
+// newSpool returns a spool in front of sink, spooling to files in dir,
+// and replaying any files left there by an earlier spool
+func newSpool(sink Sink, dir string, max int) (s *spool, err error) {
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	s = &spool{
+		sink: sink,
+		dir:  dir,
+		max:  max,
+	}
+
+	s.cond = sync.NewCond(&s.mutex)
+
+	for _, f := range files {
+		n, err := strconv.Atoi(strings.TrimSuffix(f.Name(), ".jsonl"))
+		if err == nil {
+			s.segments = append(s.segments, n)
+		}
+	}
+
+	sort.Ints(s.segments)
+
+	if len(s.segments) > 0 {
+		log.Printf("replaying %d spool files from %s", len(s.segments), dir)
+	}
+
+	go s.deliver()
+
+	return
+}
+
+// Write holds o in memory or, if memory is full, or there are outputs
+// on disk still to be replayed, appends it to disk
+func (s *spool) Write(o Output) (err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return fmt.Errorf("spool is closed")
+	}
+
+	defer s.cond.Signal()
+
+	if len(s.segments) == 0 && len(s.memory) < s.max {
+		s.memory = append(s.memory, o)
+
+		return
+	}
+
+	if s.w == nil || s.written >= spoolSegment {
+		err = s.roll()
+		if err != nil {
+			return
+		}
+	}
+
+	line, err := json.Marshal(newSpooled(o))
+	if err != nil {
+		return
+	}
+
+	_, err = s.w.Write(append(line, '\n'))
+	if err != nil {
+		// Whatever part of the line made it to disk is the last thing
+		// in the file, which reads as its end; the next write starts
+		// a new one
+		s.w.Close()
+		s.w = nil
+
+		return
+	}
+
+	s.written++
+
+	return
+}
+
+// roll starts a new spool file. Callers must hold the mutex
+func (s *spool) roll() (err error) {
+	n := 1
+	if len(s.segments) > 0 {
+		n = s.segments[len(s.segments)-1] + 1
+	}
+
+	w, err := os.OpenFile(s.path(n), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return
+	}
+
+	if s.w != nil {
+		s.w.Close()
+	}
+
+	s.w = w
+	s.written = 0
+	s.segments = append(s.segments, n)
+
+	return
+}
+
+func (s *spool) path(n int) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%010d.jsonl", n))
+}
+
+// deliver writes outputs to the sink, oldest first, until the spool
+// is closed. Outputs the sink fails to write are retried
+func (s *spool) deliver() {
+	for {
+		o, ok := s.next()
+		if !ok {
+			return
+		}
+
+		for failures := 0; ; failures++ {
+			err := s.sink.Write(o)
+			if err == nil {
+				break
+			}
+
+			if failures == 0 {
+				log.Printf("spool: %+v; retrying", err)
+			}
+
+			s.mutex.Lock()
+			closed := s.closed
+			s.mutex.Unlock()
+
+			if closed {
+				return
+			}
+
+			time.Sleep(spoolRetry)
+		}
+	}
+}
+
+// next waits for, and returns, the oldest output not yet delivered,
+// returning false once the spool is closed
+func (s *spool) next() (o Output, ok bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for !s.closed {
+		if len(s.memory) > 0 {
+			o = s.memory[0]
+			s.memory = s.memory[1:]
+
+			return o, true
+		}
+
+		if len(s.segments) > 0 {
+			o, ok = s.read()
+			if ok {
+				return
+			}
+
+			continue
+		}
+
+		s.cond.Wait()
+	}
+
+	return
+}
+
+// read reads the next output from disk, moving on to the next spool
+// file, and removing the last, when it reaches the end of one. It
+// returns false when there's nothing left to read. Callers must hold
+// the mutex
+func (s *spool) read() (o Output, ok bool) {
+	for len(s.segments) > 0 {
+		n := s.segments[0]
+
+		if s.r == nil {
+			f, err := os.Open(s.path(n))
+			if err != nil {
+				log.Printf("spool: %+v", err)
+				s.segments = s.segments[1:]
+
+				continue
+			}
+
+			s.rfile = f
+			s.r = bufio.NewReader(f)
+		}
+
+		line, err := s.r.ReadBytes('\n')
+		if err == nil {
+			sp := new(spooled)
+
+			err = json.Unmarshal(line, sp)
+			if err != nil {
+				log.Printf("spool: skipping %q: %+v", line, err)
+
+				continue
+			}
+
+			return sp.output(), true
+		}
+
+		if err != io.EOF {
+			log.Printf("spool: %+v", err)
+		}
+
+		// Writes are whole lines, made while holding the mutex, so the
+		// end of a file is the end of it, even if it's the one being
+		// written: once it's removed, writes go back to memory
+		s.rfile.Close()
+		s.r = nil
+		s.segments = s.segments[1:]
+
+		if len(s.segments) == 0 && s.w != nil {
+			s.w.Close()
+			s.w = nil
+		}
+
+		os.Remove(s.path(n))
+	}
+
+	return
+}
+
+// Close stops the spool, without waiting on any write to its sink
+// already under way. Outputs on disk are left to be replayed by the
+// next spool; those in memory are lost
+func (s *spool) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.closed = true
+	s.cond.Broadcast()
+
+	if s.w != nil {
+		s.w.Close()
+		s.w = nil
+	}
+
+	if s.rfile != nil {
+		s.rfile.Close()
+		s.r, s.rfile = nil, nil
+	}
+
+	return nil
+}
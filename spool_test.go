@@ -0,0 +1,287 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-lo/go-lo"
+)
+
+// gatedSink blocks writes until its gate is closed, then sends
+// outputs down out
+type gatedSink struct {
+	gate chan struct{}
+	out  chan Output
+}
+
+func (s gatedSink) Write(o Output) error {
+	<-s.gate
+	s.out <- o
+
+	return nil
+}
+
+func (gatedSink) Close() error {
+	return nil
+}
+
+func spoolOutput(i int) (o Output) {
+	o.SequenceID = fmt.Sprintf("%d", i)
+	if i%7 == 0 {
+		o.Error = fmt.Errorf("error %d", i)
+	}
+
+	return
+}
+
+func expectSpooled(t *testing.T, out chan Output, from, to int) {
+	t.Helper()
+
+	for i := from; i < to; i++ {
+		select {
+		case o := <-out:
+			expect := spoolOutput(i)
+			if expect.SequenceID != o.SequenceID {
+				t.Fatalf("expected %q, received %q", expect.SequenceID, o.SequenceID)
+			}
+
+			if fmt.Sprint(expect.Error) != fmt.Sprint(o.Error) {
+				t.Errorf("expected %v, received %v", expect.Error, o.Error)
+			}
+
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for output %d", i)
+		}
+	}
+}
+
+func TestSpool(t *testing.T) {
+	dir, err := ioutil.TempDir(td, "spool")
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	sink := gatedSink{gate: make(chan struct{}), out: make(chan Output)}
+
+	s, err := newSpool(sink, dir, 10)
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	defer s.Close()
+
+	outputs := 2*spoolSegment + 500
+	for i := 0; i < outputs; i++ {
+		err = s.Write(spoolOutput(i))
+		if err != nil {
+			t.Fatalf("unexpected error %+v", err)
+		}
+	}
+
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 3 {
+		t.Errorf("expected 3 spool files, received %d", len(files))
+	}
+
+	close(sink.gate)
+	expectSpooled(t, sink.out, 0, outputs)
+
+	// Once the spool files are replayed, writes go back to memory
+	for i := 0; i < 100 && len(files) > 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+		files, _ = ioutil.ReadDir(dir)
+	}
+
+	if len(files) != 0 {
+		t.Errorf("expected spool files to be removed, received %d", len(files))
+	}
+
+	err = s.Write(spoolOutput(outputs))
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	expectSpooled(t, sink.out, outputs, outputs+1)
+
+	files, _ = ioutil.ReadDir(dir)
+	if len(files) != 0 {
+		t.Errorf("expected output to be held in memory, received %d spool files", len(files))
+	}
+}
+
+func TestSpool_Restart(t *testing.T) {
+	dir, err := ioutil.TempDir(td, "spool-restart")
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	// With no memory to speak of, everything goes to disk, and stays
+	// there while the sink is blocked
+	blocked := make(blockedSink)
+	defer close(blocked)
+
+	s, err := newSpool(blocked, dir, 0)
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	for i := 0; i < 50; i++ {
+		err = s.Write(spoolOutput(i))
+		if err != nil {
+			t.Fatalf("unexpected error %+v", err)
+		}
+	}
+
+	s.Close()
+
+	err = s.Write(spoolOutput(50))
+	if err == nil {
+		t.Errorf("expected error")
+	}
+
+	out := make(chan Output)
+
+	s, err = newSpool(chanSink(out), dir, 10)
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	defer s.Close()
+
+	expectSpooled(t, out, 0, 50)
+}
+
+func TestSpooled(t *testing.T) {
+	ts, _ := time.Parse(time.RFC3339Nano, "2018-07-28T14:19:16.343573885+01:00")
+
+	for _, test := range []struct {
+		name   string
+		output Output
+	}{
+		{"empty", Output{}},
+		{"every field", Output{Output: golo.Output{SequenceID: "abc123", URL: "http://example.com/", Method: "GET", Status: 200, Size: 5252, Timestamp: ts, Duration: time.Second}, Scenario: "browse"}},
+		{"error", Output{Output: golo.Output{SequenceID: "abc123", Error: fmt.Errorf("an error")}}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			line, err := json.Marshal(newSpooled(test.output))
+			if err != nil {
+				t.Fatalf("unexpected error %+v", err)
+			}
+
+			var sp spooled
+
+			err = json.Unmarshal(line, &sp)
+			if err != nil {
+				t.Fatalf("unexpected error %+v", err)
+			}
+
+			o := sp.output()
+			if fmt.Sprint(test.output.Error) != fmt.Sprint(o.Error) {
+				t.Errorf("expected %v, received %v", test.output.Error, o.Error)
+			}
+
+			test.output.Error, o.Error = nil, nil
+			if !o.Timestamp.Equal(test.output.Timestamp) {
+				t.Errorf("expected %s, received %s", test.output.Timestamp, o.Timestamp)
+			}
+
+			test.output.Timestamp, o.Timestamp = time.Time{}, time.Time{}
+			if !reflect.DeepEqual(test.output, o) {
+				t.Errorf("expected %+v, received %+v", test.output, o)
+			}
+		})
+	}
+}
+
+func TestSpool_WriteFailure(t *testing.T) {
+	dir, err := ioutil.TempDir(td, "spool-failure")
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	blocked := make(blockedSink)
+	defer close(blocked)
+
+	s, err := newSpool(blocked, dir, 0)
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	defer s.Close()
+
+	err = s.Write(spoolOutput(0))
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	// The file being written to goes away from under the spool
+	s.mutex.Lock()
+	s.w.Close()
+	s.mutex.Unlock()
+
+	err = s.Write(spoolOutput(1))
+	if err == nil {
+		t.Errorf("expected error")
+	}
+
+	if s.written != 1 {
+		t.Errorf("expected 1 output written, received %d", s.written)
+	}
+
+	err = s.Write(spoolOutput(2))
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 2 {
+		t.Errorf("expected writes to move on to a new spool file, received %d files", len(files))
+	}
+}
+
+func TestSpool_Retry(t *testing.T) {
+	dir, err := ioutil.TempDir(td, "spool-retry")
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	out := make(chan Output, 1)
+	sink := &flakySink{out: out, failures: 1}
+
+	s, err := newSpool(sink, dir, 10)
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	defer s.Close()
+
+	s.Write(spoolOutput(0))
+
+	expectSpooled(t, out, 0, 1)
+}
+
+// flakySink fails its first few writes
+type flakySink struct {
+	out      chan Output
+	failures int
+}
+
+func (s *flakySink) Write(o Output) error {
+	if s.failures > 0 {
+		s.failures--
+
+		return fmt.Errorf("an error")
+	}
+
+	s.out <- o
+
+	return nil
+}
+
+func (*flakySink) Close() error {
+	return nil
+}
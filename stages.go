@@ -0,0 +1,63 @@
+package main
+
+import (
+	"time"
+)
+
+// Stage is a step in a job's load profile. Over the course of a stage,
+// the number of users a job runs moves linearly from the target of the
+// previous stage (or zero, for the first stage) to the target of this one.
+//
+// This makes most common profiles simple to describe:
+//
+//   - ramp-up: a single stage to the target number of users
+//   - soak: a stage with the same target as the one before it
+//   - step: a stage with a duration of zero, followed by a soak
+//   - spike: a short stage to a high target, and another back down
+type Stage struct {
+	Users    int   `json:"users"`
+	Duration int64 `json:"duration"`
+}
+
+// usersAt returns the number of users a job should be running once
+// elapsed has passed since it started
+func (j *Job) usersAt(elapsed time.Duration) int {
+	if len(j.Stages) == 0 {
+		return j.Users
+	}
+
+	var from int
+	for _, s := range j.Stages {
+		d := time.Duration(s.Duration) * time.Second
+		if elapsed < d {
+			return from + int(float64(s.Users-from)*float64(elapsed)/float64(d))
+		}
+
+		elapsed -= d
+		from = s.Users
+	}
+
+	return from
+}
+
+// stagesDuration returns how long, in seconds, a job's stages
+// take to run, end to end
+func (j *Job) stagesDuration() (d int64) {
+	for _, s := range j.Stages {
+		d += s.Duration
+	}
+
+	return
+}
+
+// peakUsers returns the highest number of users any of a
+// job's stages run
+func (j *Job) peakUsers() (users int) {
+	for _, s := range j.Stages {
+		if s.Users > users {
+			users = s.Users
+		}
+	}
+
+	return
+}
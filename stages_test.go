@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJob_UsersAt(t *testing.T) {
+	rampUp := []Stage{{Users: 100, Duration: 10}}
+	soak := []Stage{{Users: 100, Duration: 10}, {Users: 100, Duration: 10}}
+	step := []Stage{{Users: 10, Duration: 10}, {Users: 50, Duration: 0}, {Users: 50, Duration: 10}}
+	spike := []Stage{{Users: 10, Duration: 10}, {Users: 1000, Duration: 2}, {Users: 10, Duration: 2}}
+
+	for _, test := range []struct {
+		name    string
+		job     *Job
+		elapsed time.Duration
+		expect  int
+	}{
+		{"no stages", &Job{Users: 25}, 5 * time.Second, 25},
+		{"ramp-up, start", &Job{Stages: rampUp}, 0, 0},
+		{"ramp-up, halfway", &Job{Stages: rampUp}, 5 * time.Second, 50},
+		{"ramp-up, end", &Job{Stages: rampUp}, 10 * time.Second, 100},
+		{"ramp-up, overrun", &Job{Stages: rampUp}, time.Minute, 100},
+		{"soak", &Job{Stages: soak}, 15 * time.Second, 100},
+		{"step, before", &Job{Stages: step}, 9 * time.Second, 9},
+		{"step, after", &Job{Stages: step}, 10 * time.Second, 50},
+		{"spike, before", &Job{Stages: spike}, 10 * time.Second, 10},
+		{"spike, peak", &Job{Stages: spike}, 12 * time.Second, 1000},
+		{"spike, ramp-down", &Job{Stages: spike}, 13 * time.Second, 505},
+		{"spike, after", &Job{Stages: spike}, 14 * time.Second, 10},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			received := test.job.usersAt(test.elapsed)
+			if test.expect != received {
+				t.Errorf("expected %d users, received %d", test.expect, received)
+			}
+		})
+	}
+}
+
+func TestJob_StagesDuration(t *testing.T) {
+	j := Job{Stages: []Stage{{Users: 10, Duration: 10}, {Users: 50, Duration: 0}, {Users: 50, Duration: 20}}}
+
+	if j.stagesDuration() != 30 {
+		t.Errorf("expected 30, received %d", j.stagesDuration())
+	}
+
+	if j.peakUsers() != 50 {
+		t.Errorf("expected 50, received %d", j.peakUsers())
+	}
+}
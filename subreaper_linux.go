@@ -0,0 +1,21 @@
+package main
+
+import (
+	"syscall"
+)
+
+const (
+	prSetChildSubreaper = 36
+)
+
+// subreaper makes the agent the parent of any process orphaned by
+// a schedule, rather than init, so that it can reap them once the
+// schedule's job is over
+func subreaper() error {
+	_, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, prSetChildSubreaper, 1, 0)
+	if errno != 0 {
+		return errno
+	}
+
+	return nil
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+// +build !linux
+
+package main
+
+// subreaper is a no-op outside of linux; orphaned schedule
+// processes are killed, but reaped by init
+func subreaper() error {
+	return nil
+}
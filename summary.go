@@ -0,0 +1,225 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// histogramPrecision is how far, as a proportion, the latencies
+	// a histogram reports can be from those it was given
+	histogramPrecision = 0.01
+
+	// maxSummaryRequests caps the number of URL and method pairs a
+	// summary breaks results down by, so that a schedule calling a
+	// different URL every time can't eat the agent's memory. Results
+	// for pairs past the cap still count towards the total
+	maxSummaryRequests = 1000
+)
+
+var (
+	// histogramBase is the ratio between the bounds of one
+	// histogram bucket and the next
+	histogramBase = math.Log1p(histogramPrecision)
+)
+
+// histogram counts latencies, as an HDR histogram does, in buckets
+// which grow with the latencies they hold so that every bucket is within
+// histogramPrecision of its latencies, however large or small they are
+type histogram struct {
+	buckets map[int]int64
+	count   int64
+	max     time.Duration
+}
+
+func newHistogram() *histogram {
+	return &histogram{
+		buckets: make(map[int]int64),
+	}
+}
+
+func (h *histogram) record(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+
+	h.buckets[bucket(d)]++
+	h.count++
+
+	if d > h.max {
+		h.max = d
+	}
+}
+
+// percentile returns the latency p percent of those recorded are at, or
+// under. Latencies are reported as the upper bound of their bucket, short
+// of the largest, which is reported exactly
+func (h *histogram) percentile(p float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+
+	keys := make([]int, 0, len(h.buckets))
+	for k := range h.buckets {
+		keys = append(keys, k)
+	}
+
+	sort.Ints(keys)
+
+	rank := int64(math.Ceil(p / 100 * float64(h.count)))
+
+	var seen int64
+	for _, k := range keys {
+		seen += h.buckets[k]
+		if seen >= rank {
+			d := bound(k)
+			if d > h.max {
+				d = h.max
+			}
+
+			return d
+		}
+	}
+
+	return h.max
+}
+
+// bucket returns the index of the histogram bucket d falls in
+func bucket(d time.Duration) int {
+	if d < 1 {
+		return 0
+	}
+
+	return int(math.Log(float64(d))/histogramBase) + 1
+}
+
+// bound returns the largest latency in bucket i
+func bound(i int) time.Duration {
+	if i == 0 {
+		return 0
+	}
+
+	return time.Duration(math.Exp(float64(i) * histogramBase))
+}
+
+// stats are the numbers a summary keeps for a set of results
+type stats struct {
+	URL      string
+	Method   string
+	Count    int64
+	Errors   int64
+	Bytes    int64
+	Statuses map[int]int64
+
+	latencies *histogram
+}
+
+func newStats(url, method string) *stats {
+	return &stats{
+		URL:       url,
+		Method:    method,
+		Statuses:  make(map[int]int64),
+		latencies: newHistogram(),
+	}
+}
+
+func (s *stats) add(o Output) {
+	s.Count++
+	s.Bytes += o.Size
+	s.Statuses[o.Status]++
+	s.latencies.record(o.Duration)
+
+	if o.Error != nil {
+		s.Errors++
+	}
+}
+
+// errorRate is the proportion of results which errored
+func (s *stats) errorRate() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+
+	return float64(s.Errors) / float64(s.Count)
+}
+
+// summary aggregates a job's results, in total and by URL and method,
+// so that how a test went can be seen without a collector
+type summary struct {
+	mutex    sync.Mutex
+	total    *stats
+	requests map[[2]string]*stats
+	first    time.Time
+	last     time.Time
+}
+
+func newSummary() *summary {
+	return &summary{
+		total:    newStats("", ""),
+		requests: make(map[[2]string]*stats),
+	}
+}
+
+// add counts o towards the summary. A nil summary ignores it
+func (s *summary) add(o Output) {
+	if s == nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.total == nil {
+		return
+	}
+
+	now := time.Now()
+	if s.first.IsZero() {
+		s.first = now
+	}
+
+	s.last = now
+
+	s.total.add(o)
+
+	key := [2]string{o.URL, o.Method}
+
+	r, ok := s.requests[key]
+	if !ok {
+		if len(s.requests) >= maxSummaryRequests {
+			return
+		}
+
+		r = newStats(o.URL, o.Method)
+		s.requests[key] = r
+	}
+
+	r.add(o)
+}
+
+// close drops a summary's stats, once they're no longer needed. A
+// closed summary ignores whatever it's given
+func (s *summary) close() {
+	if s == nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.total = nil
+	s.requests = nil
+}
+
+// throughput is the number of results a second, over the time
+// from the first result to the last, of a set of stats
+func (s *summary) throughput(st *stats) float64 {
+	elapsed := s.last.Sub(s.first).Seconds()
+	if elapsed < 1 {
+		elapsed = 1
+	}
+
+	return float64(st.Count) / elapsed
+}
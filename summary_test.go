@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/go-lo/go-lo"
+)
+
+func TestHistogram(t *testing.T) {
+	h := newHistogram()
+
+	// 1ms through 10s, evenly
+	for i := 1; i <= 10000; i++ {
+		h.record(time.Duration(i) * time.Millisecond)
+	}
+
+	for _, test := range []struct {
+		percentile float64
+		expect     time.Duration
+	}{
+		{50, 5 * time.Second},
+		{90, 9 * time.Second},
+		{99, 9900 * time.Millisecond},
+		{99.9, 9990 * time.Millisecond},
+		{100, 10 * time.Second},
+	} {
+		t.Run(fmt.Sprint(test.percentile), func(t *testing.T) {
+			received := h.percentile(test.percentile)
+
+			if math.Abs(float64(received-test.expect)) > float64(test.expect)*histogramPrecision {
+				t.Errorf("expected %s, give or take 1%%, received %s", test.expect, received)
+			}
+		})
+	}
+
+	if h.max != 10*time.Second {
+		t.Errorf("expected %s, received %s", 10*time.Second, h.max)
+	}
+}
+
+func TestHistogram_Empty(t *testing.T) {
+	if d := newHistogram().percentile(99); d != 0 {
+		t.Errorf("expected 0, received %s", d)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	s := newSummary()
+
+	for _, o := range []golo.Output{
+		{URL: "http://example.com/", Method: "GET", Status: 200, Size: 100, Duration: time.Millisecond},
+		{URL: "http://example.com/", Method: "GET", Status: 200, Size: 100, Duration: 3 * time.Millisecond},
+		{URL: "http://example.com/", Method: "GET", Status: 500, Size: 10, Duration: 2 * time.Millisecond, Error: fmt.Errorf("an error")},
+		{URL: "http://example.com/basket", Method: "POST", Status: 201, Size: 5, Duration: 10 * time.Millisecond},
+		{URL: "http://example.com/", Method: "HEAD", Status: 200},
+	} {
+		s.add(Output{Output: o})
+	}
+
+	sp := summaryProto(s)
+
+	t.Run("total", func(t *testing.T) {
+		if sp.Total.Count != 5 {
+			t.Errorf("expected %d, received %d", 5, sp.Total.Count)
+		}
+
+		if sp.Total.Errors != 1 {
+			t.Errorf("expected %d, received %d", 1, sp.Total.Errors)
+		}
+
+		if sp.Total.ErrorRate != 0.2 {
+			t.Errorf("expected %v, received %v", 0.2, sp.Total.ErrorRate)
+		}
+
+		if sp.Total.Bytes != 215 {
+			t.Errorf("expected %d, received %d", 215, sp.Total.Bytes)
+		}
+
+		expect := map[int32]uint64{200: 3, 201: 1, 500: 1}
+		if fmt.Sprint(expect) != fmt.Sprint(sp.Total.Statuses) {
+			t.Errorf("expected %v, received %v", expect, sp.Total.Statuses)
+		}
+
+		if sp.Total.Latency.Max != int64(10*time.Millisecond) {
+			t.Errorf("expected %d, received %d", int64(10*time.Millisecond), sp.Total.Latency.Max)
+		}
+
+		// Results all arrived within a second
+		if sp.Total.Throughput != 5 {
+			t.Errorf("expected %v, received %v", 5, sp.Total.Throughput)
+		}
+	})
+
+	t.Run("by url and method", func(t *testing.T) {
+		var received []string
+		for _, r := range sp.Requests {
+			received = append(received, fmt.Sprintf("%s %s %d", r.Method, r.Url, r.Count))
+		}
+
+		expect := []string{"GET http://example.com/ 3", "HEAD http://example.com/ 1", "POST http://example.com/basket 1"}
+		if fmt.Sprint(expect) != fmt.Sprint(received) {
+			t.Errorf("expected %v, received %v", expect, received)
+		}
+
+		get := sp.Requests[0]
+		if get.Latency.P50 < int64(2*time.Millisecond) || get.Latency.P50 > int64(2*time.Millisecond)*101/100 {
+			t.Errorf("expected p50 of around %d, received %d", int64(2*time.Millisecond), get.Latency.P50)
+		}
+	})
+}
+
+func TestSummary_MaxRequests(t *testing.T) {
+	s := newSummary()
+
+	for i := 0; i < maxSummaryRequests+10; i++ {
+		s.add(Output{Output: golo.Output{URL: fmt.Sprintf("http://example.com/%d", i), Method: "GET"}})
+	}
+
+	if len(s.requests) != maxSummaryRequests {
+		t.Errorf("expected %d, received %d", maxSummaryRequests, len(s.requests))
+	}
+
+	if s.total.Count != maxSummaryRequests+10 {
+		t.Errorf("expected %d, received %d", maxSummaryRequests+10, s.total.Count)
+	}
+}
+
+func TestSummary_Nil(t *testing.T) {
+	var s *summary
+
+	s.add(Output{})
+
+	if summaryProto(s) != nil {
+		t.Errorf("expected no summary")
+	}
+}
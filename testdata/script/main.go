@@ -0,0 +1,13 @@
+package main
+
+import (
+	"time"
+)
+
+// main does nothing but sit around until it is stopped, which is
+// all a schedule has to look like from the outside to the agent
+func main() {
+	for {
+		time.Sleep(time.Hour)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"math/rand"
+	"time"
+)
+
+const (
+	// ThinkFixed think times always last for Mean
+	ThinkFixed = "fixed"
+
+	// ThinkUniform think times are spread evenly between Min and Max
+	ThinkUniform = "uniform"
+
+	// ThinkExponential think times average Mean, but are mostly
+	// shorter with the occasional long one, as real users are. Max,
+	// when set, caps them
+	ThinkExponential = "exponential"
+)
+
+// ThinkTime is how long a user waits between calls, on top of the job's
+// pacing, to simulate a real user reading a page or filling in a form.
+// Times are in milliseconds. An unset Distribution means no think time
+type ThinkTime struct {
+	Distribution string `json:"distribution"`
+	Min          int64  `json:"min"`
+	Max          int64  `json:"max"`
+	Mean         int64  `json:"mean"`
+}
+
+// duration returns a think time drawn from t's distribution
+func (t ThinkTime) duration() time.Duration {
+	var ms float64
+
+	switch t.Distribution {
+	case ThinkFixed:
+		ms = float64(t.Mean)
+
+	case ThinkUniform:
+		ms = float64(t.Min)
+		if t.Max > t.Min {
+			ms += float64(rand.Int63n(t.Max - t.Min))
+		}
+
+	case ThinkExponential:
+		ms = rand.ExpFloat64() * float64(t.Mean)
+		if t.Max > 0 && ms > float64(t.Max) {
+			ms = float64(t.Max)
+		}
+	}
+
+	return time.Duration(ms * float64(time.Millisecond))
+}
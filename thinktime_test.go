@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestThinkTime_Duration(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		thinkTime ThinkTime
+		min       time.Duration
+		max       time.Duration
+	}{
+		{"none", ThinkTime{}, 0, 0},
+		{"fixed", ThinkTime{Distribution: ThinkFixed, Mean: 500}, 500 * time.Millisecond, 500 * time.Millisecond},
+		{"uniform", ThinkTime{Distribution: ThinkUniform, Min: 100, Max: 200}, 100 * time.Millisecond, 200 * time.Millisecond},
+		{"uniform, no range", ThinkTime{Distribution: ThinkUniform, Min: 100, Max: 100}, 100 * time.Millisecond, 100 * time.Millisecond},
+		{"exponential, capped", ThinkTime{Distribution: ThinkExponential, Mean: 100, Max: 150}, 0, 150 * time.Millisecond},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			for i := 0; i < 1000; i++ {
+				d := test.thinkTime.duration()
+				if d < test.min || d > test.max {
+					t.Fatalf("expected between %s and %s, received %s", test.min, test.max, d)
+				}
+			}
+		})
+	}
+
+	t.Run("exponential mean", func(t *testing.T) {
+		tt := ThinkTime{Distribution: ThinkExponential, Mean: 100}
+
+		var total time.Duration
+		for i := 0; i < 10000; i++ {
+			total += tt.duration()
+		}
+
+		mean := total / 10000
+		if mean < 90*time.Millisecond || mean > 110*time.Millisecond {
+			t.Errorf("expected a mean of around 100ms, received %s", mean)
+		}
+	})
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// thresholdExpression matches, say, "p95(duration) < 300ms",
+	// "error_rate < 1%" or "rps >= 500"
+	thresholdExpression = regexp.MustCompile(`^\s*(p\d+(?:\.\d+)?\(duration\)|max\(duration\)|error_rate|rps)\s*(<=|>=|<|>)\s*(\S+)\s*$`)
+)
+
+// Threshold is a pass/fail condition on a job's results, such as
+// "p95(duration) < 300ms", "error_rate < 1%" or "rps > 500", which the
+// agent checks as the job runs and once it's finished. A job which
+// finishes with a threshold breached fails.
+//
+// The left hand side is a percentile of latency, pNN(duration), the
+// largest latency, max(duration), the proportion of results which
+// errored, error_rate, or results a second, rps. URL, when set, scopes
+// the threshold to results for that URL alone.
+//
+// A breached threshold with Abort set stops the job straight away,
+// so long as it's been running for AbortAfter seconds; results are
+// checked from the start, so thresholds such as rps > 500 need time
+// to ramp up before they can be relied on
+type Threshold struct {
+	Expression string `json:"expression"`
+	URL        string `json:"url"`
+	Abort      bool   `json:"abort"`
+	AbortAfter int64  `json:"abortAfter"`
+}
+
+// threshold is a parsed Threshold, along with the results it's
+// checked against
+type threshold struct {
+	Threshold
+
+	mutex      sync.Mutex
+	metric     string
+	percentile float64
+	op         string
+	value      float64
+	stats      *stats
+	first      time.Time
+	last       time.Time
+}
+
+// parseThreshold parses the expression of t
+func parseThreshold(t Threshold) (th *threshold, err error) {
+	m := thresholdExpression.FindStringSubmatch(t.Expression)
+	if m == nil {
+		return nil, fmt.Errorf("invalid threshold %q", t.Expression)
+	}
+
+	th = &threshold{
+		Threshold: t,
+		metric:    m[1],
+		op:        m[2],
+		stats:     newStats(t.URL, ""),
+	}
+
+	switch {
+	case th.metric == "error_rate":
+		th.value, err = parseRate(m[3])
+
+	case th.metric == "rps":
+		th.value, err = strconv.ParseFloat(m[3], 64)
+
+	default:
+		if th.metric != "max(duration)" {
+			th.percentile, err = strconv.ParseFloat(strings.TrimSuffix(th.metric[1:], "(duration)"), 64)
+			if err == nil && th.percentile > 100 {
+				err = fmt.Errorf("percentile must be at most 100")
+			}
+
+			if err != nil {
+				break
+			}
+		}
+
+		var d time.Duration
+
+		d, err = time.ParseDuration(m[3])
+		th.value = float64(d)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid threshold %q: %s", t.Expression, err)
+	}
+
+	return
+}
+
+// parseThresholds parses each of ts
+func parseThresholds(ts []Threshold) (ths []*threshold, err error) {
+	for _, t := range ts {
+		var th *threshold
+
+		th, err = parseThreshold(t)
+		if err != nil {
+			return nil, err
+		}
+
+		ths = append(ths, th)
+	}
+
+	return
+}
+
+// parseRate parses a proportion, either as a percentage, like
+// "1%", or as it is, like "0.01"
+func parseRate(s string) (f float64, err error) {
+	if strings.HasSuffix(s, "%") {
+		f, err = strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+
+		return f / 100, err
+	}
+
+	return strconv.ParseFloat(s, 64)
+}
+
+// add counts o towards the threshold, if it's in scope
+func (t *threshold) add(o Output) {
+	if t.URL != "" && t.URL != o.URL {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := time.Now()
+	if t.first.IsZero() {
+		t.first = now
+	}
+
+	t.last = now
+
+	t.stats.add(o)
+}
+
+// check returns whether the threshold holds, and the value it
+// was checked with
+func (t *threshold) check() (ok bool, observed float64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	switch t.metric {
+	case "error_rate":
+		observed = t.stats.errorRate()
+
+	case "rps":
+		elapsed := t.last.Sub(t.first).Seconds()
+		if elapsed < 1 {
+			elapsed = 1
+		}
+
+		observed = float64(t.stats.Count) / elapsed
+
+	case "max(duration)":
+		observed = float64(t.stats.latencies.max)
+
+	default:
+		observed = float64(t.stats.latencies.percentile(t.percentile))
+	}
+
+	switch t.op {
+	case "<":
+		ok = observed < t.value
+	case "<=":
+		ok = observed <= t.value
+	case ">":
+		ok = observed > t.value
+	case ">=":
+		ok = observed >= t.value
+	}
+
+	return
+}
+
+// format formats observed in the units of the threshold's metric
+func (t *threshold) format(observed float64) string {
+	switch t.metric {
+	case "error_rate":
+		return strconv.FormatFloat(observed*100, 'f', 2, 64) + "%"
+	case "rps":
+		return strconv.FormatFloat(observed, 'f', 2, 64)
+	}
+
+	return time.Duration(observed).String()
+}
+
+func (t *threshold) String() string {
+	if t.URL == "" {
+		return t.Expression
+	}
+
+	return fmt.Sprintf("%s for %s", t.Expression, t.URL)
+}
+
+// breached returns an error describing the job's breached thresholds,
+// if any are. With abort set, only thresholds which abort the job, and
+// have been given long enough to, are checked
+func (j *Job) breached(abort bool, running time.Duration) error {
+	var failed []string
+
+	for _, t := range j.thresholds {
+		if abort && (!t.Abort || running < time.Duration(t.AbortAfter)*time.Second) {
+			continue
+		}
+
+		ok, observed := t.check()
+		if !ok {
+			failed = append(failed, fmt.Sprintf("%s (was %s)", t, t.format(observed)))
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%s breached thresholds: %s", j.Name, strings.Join(failed, ", "))
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff"
+	"github.com/go-lo/go-lo"
+)
+
+func TestParseThreshold(t *testing.T) {
+	for _, test := range []struct {
+		expression  string
+		metric      string
+		percentile  float64
+		op          string
+		value       float64
+		expectError bool
+	}{
+		{"p95(duration) < 300ms", "p95(duration)", 95, "<", float64(300 * time.Millisecond), false},
+		{"p99.9(duration)<=1s", "p99.9(duration)", 99.9, "<=", float64(time.Second), false},
+		{"max(duration) < 2s", "max(duration)", 0, "<", float64(2 * time.Second), false},
+		{"error_rate < 1%", "error_rate", 0, "<", 0.01, false},
+		{"error_rate <= 0.05", "error_rate", 0, "<=", 0.05, false},
+		{"rps > 500", "rps", 0, ">", 500, false},
+		{" rps >= 12.5 ", "rps", 0, ">=", 12.5, false},
+		{"p95(duration) < 300", "", 0, "", 0, true},
+		{"p101(duration) < 1s", "", 0, "", 0, true},
+		{"error_rate < lots", "", 0, "", 0, true},
+		{"rps == 500", "", 0, "", 0, true},
+		{"mean(duration) < 1s", "", 0, "", 0, true},
+		{"", "", 0, "", 0, true},
+	} {
+		t.Run(test.expression, func(t *testing.T) {
+			th, err := parseThreshold(Threshold{Expression: test.expression})
+			if test.expectError && err == nil {
+				t.Errorf("expected error")
+			}
+
+			if !test.expectError && err != nil {
+				t.Errorf("unexpected error %+v", err)
+			}
+
+			if test.expectError {
+				return
+			}
+
+			received := fmt.Sprint(th.metric, th.percentile, th.op, th.value)
+			expect := fmt.Sprint(test.metric, test.percentile, test.op, test.value)
+
+			if expect != received {
+				t.Errorf("expected %q, received %q", expect, received)
+			}
+		})
+	}
+}
+
+func TestThreshold_Check(t *testing.T) {
+	outputs := []golo.Output{
+		{URL: "http://example.com/", Duration: 100 * time.Millisecond},
+		{URL: "http://example.com/", Duration: 200 * time.Millisecond},
+		{URL: "http://example.com/", Duration: 300 * time.Millisecond, Error: fmt.Errorf("an error")},
+		{URL: "http://example.com/slow", Duration: 2 * time.Second},
+	}
+
+	for _, test := range []struct {
+		threshold Threshold
+		expect    bool
+		observed  string
+	}{
+		{Threshold{Expression: "p50(duration) < 250ms"}, true, "200ms"},
+		{Threshold{Expression: "max(duration) < 1s"}, false, "2s"},
+		{Threshold{Expression: "max(duration) < 1s", URL: "http://example.com/"}, true, "300ms"},
+		{Threshold{Expression: "error_rate < 20%"}, false, "25.00%"},
+		{Threshold{Expression: "error_rate < 20%", URL: "http://example.com/slow"}, true, "0.00%"},
+		{Threshold{Expression: "rps >= 4"}, true, "4.00"},
+		{Threshold{Expression: "rps > 4"}, false, "4.00"},
+	} {
+		t.Run(fmt.Sprintf("%s for %q", test.threshold.Expression, test.threshold.URL), func(t *testing.T) {
+			th, err := parseThreshold(test.threshold)
+			if err != nil {
+				t.Fatalf("unexpected error %+v", err)
+			}
+
+			for _, o := range outputs {
+				th.add(Output{Output: o})
+			}
+
+			ok, observed := th.check()
+			if test.expect != ok {
+				t.Errorf("expected %v, received %v", test.expect, ok)
+			}
+
+			// Latencies are within 1% of what they were
+			if !strings.HasPrefix(th.format(observed), strings.TrimSuffix(test.observed, "ms")) {
+				t.Errorf("expected %q, received %q", test.observed, th.format(observed))
+			}
+		})
+	}
+}
+
+func TestJob_Breached(t *testing.T) {
+	ths, err := parseThresholds([]Threshold{
+		{Expression: "rps > 100"},
+		{Expression: "max(duration) < 1s", Abort: true, AbortAfter: 10},
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error %+v", err)
+	}
+
+	j := Job{Name: "test", thresholds: ths}
+	for _, th := range ths {
+		th.add(Output{Output: golo.Output{Duration: 2 * time.Second}})
+	}
+
+	for _, test := range []struct {
+		name    string
+		abort   bool
+		running time.Duration
+		expect  string
+	}{
+		{"finished", false, 0, "test breached thresholds: rps > 100 (was 1.00), max(duration) < 1s (was 2s)"},
+		{"too early to abort", true, 5 * time.Second, ""},
+		{"aborting", true, 10 * time.Second, "test breached thresholds: max(duration) < 1s (was 2s)"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			err := j.breached(test.abort, test.running)
+
+			var received string
+			if err != nil {
+				received = err.Error()
+			}
+
+			if test.expect != received {
+				t.Errorf("expected %q, received %q", test.expect, received)
+			}
+		})
+	}
+}
+
+func TestFakeRuntime_Thresholds(t *testing.T) {
+	expoBackoff = backoff.NewExponentialBackOff()
+	expoBackoff.MaxElapsedTime = time.Second
+
+	logDir = &td
+	RPCCommand = "Server.Run"
+
+	for _, test := range []struct {
+		name       string
+		duration   int64
+		thresholds []Threshold
+		expect     string
+	}{
+		{"passing", 1, []Threshold{{Expression: "error_rate < 1%"}, {Expression: "p99(duration) < 1s"}}, ""},
+		{"failing", 1, []Threshold{{Expression: "rps > 1000000"}}, "breached thresholds: rps > 1000000"},
+		{"aborting", 30, []Threshold{{Expression: "max(duration) >= 1h", Abort: true}}, "breached thresholds: max(duration) >= 1h"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+
+			j := Job{
+				Name:       "fake",
+				Duration:   test.duration,
+				Pacing:     10,
+				Users:      2,
+				Thresholds: test.thresholds,
+				runtime:    new(fakeRuntime),
+			}
+
+			start := time.Now()
+
+			err := j.Start(discardSink{})
+
+			var received string
+			if err != nil {
+				received = err.Error()
+			}
+
+			if !strings.Contains(received, test.expect) || (test.expect == "") != (err == nil) {
+				t.Errorf("expected %q, received %q", test.expect, received)
+			}
+
+			if time.Since(start) > 10*time.Second {
+				t.Errorf("expected job to finish early, took %s", time.Since(start))
+			}
+		})
+	}
+}